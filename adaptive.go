@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/planner"
+	"github.com/pavelpuchok/insightcourier/storage"
+)
+
+// activityWindow is the period of the source history used to estimate its posting rate.
+const activityWindow = 30 * 24 * time.Hour
+
+// AdaptivePolling moves the source polling interval between bounds following its posting rate.
+type AdaptivePolling struct {
+	Schedule    *planner.AdaptiveSchedule
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+// updateHinter is implemented by fetchers of feeds which can ask not to be polled too often.
+type updateHinter interface {
+	UpdateHint() time.Duration
+}
+
+// adaptPollInterval recalculates the source polling interval and persists it if it has changed.
+func (w *Worker) adaptPollInterval(ctx context.Context, job storage.Job, src Source) error {
+	a, err := w.Storage.GetSourceActivity(ctx, job.SourceName, time.Now().Add(-activityWindow))
+	if err != nil {
+		return err
+	}
+
+	var hint time.Duration
+	if h, ok := src.Fetcher.(updateHinter); ok {
+		hint = h.UpdateHint()
+	}
+
+	interval := pollInterval(a, hint, src.Adaptive.MinInterval, src.Adaptive.MaxInterval)
+	if interval == src.Adaptive.Schedule.Interval() {
+		return nil
+	}
+
+	if err := w.Storage.SetSourcePollInterval(ctx, job.SourceName, interval); err != nil {
+		return fmt.Errorf("failed to save poll interval. %w", err)
+	}
+	src.Adaptive.Schedule.SetInterval(interval)
+
+	slog.Info("Source poll interval changed",
+		slog.String("source.name", job.SourceName),
+		slog.Duration("interval", interval),
+		slog.Int("items", a.Items),
+		slog.Duration("hint", hint),
+	)
+
+	return nil
+}
+
+// pollInterval returns interval to poll the source twice per its average posting interval, but not
+// more often than the publisher asks. Sources without recent items are polled at maxInterval.
+func pollInterval(a storage.SourceActivity, hint, minInterval, maxInterval time.Duration) time.Duration {
+	d := maxInterval
+	if a.Items > 1 {
+		d = a.Last.Sub(a.First) / time.Duration(a.Items-1) / 2
+	}
+	d = max(d, hint)
+
+	// rounding keeps the interval stable between fetches of a steady feed
+	return min(max(d.Round(time.Minute), minInterval), maxInterval)
+}
+
+// newAdaptivePolling restores the persisted polling interval of the source, the initial interval
+// is used for sources polled the first time.
+func newAdaptivePolling(ctx context.Context, s *storage.PostgreSQL, source string, initial, minInterval, maxInterval time.Duration) (*AdaptivePolling, error) {
+	interval, err := s.GetSourcePollInterval(ctx, source)
+	if err != nil && !errors.Is(err, storage.ErrSourceNotFound) {
+		return nil, err
+	}
+	if interval == 0 {
+		interval = initial
+	}
+
+	return &AdaptivePolling{
+		Schedule:    planner.NewAdaptive(min(max(interval, minInterval), maxInterval)),
+		MinInterval: minInterval,
+		MaxInterval: maxInterval,
+	}, nil
+}
@@ -0,0 +1,208 @@
+package canonical
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/publicsuffix"
+)
+
+// trackingParams are query parameters which don't affect page content.
+var trackingParams = map[string]struct{}{
+	"fbclid":      {},
+	"gclid":       {},
+	"dclid":       {},
+	"msclkid":     {},
+	"yclid":       {},
+	"igshid":      {},
+	"mc_cid":      {},
+	"mc_eid":      {},
+	"_hsenc":      {},
+	"_hsmi":       {},
+	"mkt_tok":     {},
+	"ref_src":     {},
+	"ref_url":     {},
+	"cmpid":       {},
+	"ncid":        {},
+	"spm":         {},
+	"s_cid":       {},
+	"oly_enc_id":  {},
+	"oly_anon_id": {},
+	"vero_id":     {},
+	"wt_mc":       {},
+	"__s":         {},
+}
+
+// trackingParamPrefixes are prefixes of tracking query parameter families.
+var trackingParamPrefixes = []string{"utm_", "pk_", "mtm_", "ga_"}
+
+// shorteners are hosts which only redirect to the actual article.
+var shorteners = map[string]struct{}{
+	"t.co":                 {},
+	"bit.ly":               {},
+	"buff.ly":              {},
+	"ow.ly":                {},
+	"tinyurl.com":          {},
+	"goo.gl":               {},
+	"dlvr.it":              {},
+	"trib.al":              {},
+	"lnkd.in":              {},
+	"fb.me":                {},
+	"ift.tt":               {},
+	"redd.it":              {},
+	"feedproxy.google.com": {},
+	"feeds.feedburner.com": {},
+	"hubs.ly":              {},
+	"shorturl.at":          {},
+	"rebrand.ly":           {},
+	"cutt.ly":              {},
+	"is.gd":                {},
+	"amzn.to":              {},
+	"youtu.be":             {},
+	"flip.it":              {},
+	"l.facebook.com":       {},
+	"out.reddit.com":       {},
+}
+
+// Clean normalizes URL: lowercases scheme and host, drops default port, fragment and
+// tracking query parameters, and sorts the remaining parameters.
+func Clean(link string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s. %w", link, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	q := u.Query()
+	for k := range q {
+		if isTrackingParam(k) {
+			q.Del(k)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func isTrackingParam(k string) bool {
+	k = strings.ToLower(k)
+	if _, has := trackingParams[k]; has {
+		return true
+	}
+	for _, p := range trackingParamPrefixes {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromPage returns cleaned <link rel="canonical"> URL of the page, or empty string if the page has none.
+// Canonicals of another site or of the site root are ignored: sites pointing every page to the homepage
+// would otherwise collapse all their articles into one.
+func FromPage(pageURL string, html string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return ""
+	}
+
+	href, has := doc.Find(`link[rel="canonical"][href]`).First().Attr("href")
+	if !has {
+		return ""
+	}
+
+	u, err := base.Parse(strings.TrimSpace(href))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	if u.Path == "" || u.Path == "/" || !sameSite(base.Hostname(), u.Hostname()) {
+		return ""
+	}
+
+	c, err := Clean(u.String())
+	if err != nil {
+		return ""
+	}
+	return c
+}
+
+// sameSite reports whether hosts belong to the same registrable domain, e.g. www.example.com
+// and blog.example.com.
+func sameSite(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if a == b {
+		return true
+	}
+
+	da, err := publicsuffix.EffectiveTLDPlusOne(a)
+	if err != nil {
+		return false
+	}
+	db, err := publicsuffix.EffectiveTLDPlusOne(b)
+	if err != nil {
+		return false
+	}
+	return da == db
+}
+
+// Canonicalizer resolves shortened links and cleans them.
+type Canonicalizer struct {
+	client *http.Client
+}
+
+func New() *Canonicalizer {
+	return &Canonicalizer{
+		client: http.DefaultClient,
+	}
+}
+
+// Canonicalize follows redirects of known link shorteners and returns cleaned URL of the target.
+// If redirect can't be followed, the cleaned original link is returned.
+func (c *Canonicalizer) Canonicalize(ctx context.Context, link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s. %w", link, err)
+	}
+
+	if _, has := shorteners[strings.ToLower(u.Hostname())]; has {
+		link = c.resolve(ctx, link)
+	}
+
+	return Clean(link)
+}
+
+func (c *Canonicalizer) resolve(ctx context.Context, link string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil)
+	if err != nil {
+		return link
+	}
+
+	r, err := c.client.Do(req)
+	if err != nil {
+		slog.Warn("Failed to resolve link redirect", slog.String("link", link), slog.String("error", err.Error()))
+		return link
+	}
+	r.Body.Close()
+
+	return r.Request.URL.String()
+}
@@ -0,0 +1,62 @@
+package canonical
+
+import "testing"
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{link: "HTTPS://Example.COM:443/a?b=2&a=1#top", want: "https://example.com/a?a=1&b=2"},
+		{link: "http://example.com:80", want: "http://example.com/"},
+		{link: "http://example.com:8080/a", want: "http://example.com:8080/a"},
+		{link: "https://example.com/a?utm_source=x&UTM_Medium=y&fbclid=z&id=7", want: "https://example.com/a?id=7"},
+		{link: "  https://example.com/a  ", want: "https://example.com/a"},
+	}
+
+	for _, tt := range tests {
+		got, err := Clean(tt.link)
+		if err != nil {
+			t.Errorf("Clean(%q) failed: %v", tt.link, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Clean(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestFromPage(t *testing.T) {
+	const pageURL = "https://www.example.com/2026/10/post?utm_source=rss"
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{name: "absolute", html: `<link rel="canonical" href="https://www.example.com/2026/10/post#comments">`, want: "https://www.example.com/2026/10/post"},
+		{name: "relative", html: `<link rel="canonical" href="/posts/42?utm_medium=feed">`, want: "https://www.example.com/posts/42"},
+		{name: "subdomain", html: `<link rel="canonical" href="https://blog.example.com/post">`, want: "https://blog.example.com/post"},
+		{name: "missing", html: `<p>no canonical</p>`, want: ""},
+		{name: "root", html: `<link rel="canonical" href="https://www.example.com/">`, want: ""},
+		{name: "empty path", html: `<link rel="canonical" href="https://www.example.com">`, want: ""},
+		{name: "other site", html: `<link rel="canonical" href="https://aggregator.com/2026/10/post">`, want: ""},
+		{name: "other site of public suffix", html: `<link rel="canonical" href="https://example.co.uk/post">`, want: ""},
+		{name: "not HTTP", html: `<link rel="canonical" href="ftp://www.example.com/post">`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromPage(pageURL, tt.html); got != tt.want {
+				t.Errorf("FromPage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromPageSharedSuffix(t *testing.T) {
+	// different owners under the same public suffix aren't the same site
+	got := FromPage("https://alice.github.io/post", `<link rel="canonical" href="https://bob.github.io/post">`)
+	if got != "" {
+		t.Errorf("FromPage() = %q, want canonical of another site ignored", got)
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
+
+	"github.com/pavelpuchok/insightcourier/planner"
 )
 
 type EnvVarProvider struct {
@@ -14,12 +17,111 @@ type EnvVarProvider struct {
 
 type RSSSourceConfig struct {
 	FeedURL        string        `json:"feedUrl"`
+	SiteURL        string        `json:"siteUrl"`
+	OnUpdate       string        `json:"onUpdate"`
 	UpdateInterval time.Duration `json:"updateInterval"`
+	Schedule       string        `json:"schedule"`
+	FetchStrategy  string        `json:"fetchStrategy"`
+	// Adaptive enables polling interval changing between MinInterval and MaxInterval
+	// according to the feed activity, UpdateInterval is used as the initial one.
+	Adaptive    bool          `json:"adaptive"`
+	MinInterval time.Duration `json:"minInterval"`
+	MaxInterval time.Duration `json:"maxInterval"`
+}
+
+// Policies of handling items which were updated by the publisher after being reported.
+const (
+	ItemUpdateIgnore = "ignore"
+	ItemUpdateReport = "report"
+	ItemUpdateEdit   = "edit"
+)
+
+// Strategies of getting item pages. Auto tries a direct request first and falls back to FlareSolverr
+// when the site serves an anti-bot challenge.
+const (
+	FetchStrategyAuto         = "auto"
+	FetchStrategyDirect       = "direct"
+	FetchStrategyFlareSolverr = "flaresolverr"
+)
+
+type RedditSourceConfig struct {
+	Subreddit      string        `json:"subreddit"`
+	Sort           string        `json:"sort"`
+	TimeWindow     string        `json:"timeWindow"`
+	Limit          int           `json:"limit"`
+	UpdateInterval time.Duration `json:"updateInterval"`
+	Schedule       string        `json:"schedule"`
+	FetchStrategy  string        `json:"fetchStrategy"`
+}
+
+// RedditUserSourceConfig configures a Reddit user source. Items scored lower than MinScore are held back
+// and checked again on every fetch until they reach it or get older than ScoreWindow. Zero MinScore
+// disables the filter.
+type RedditUserSourceConfig struct {
+	Username        string        `json:"username"`
+	IncludeComments bool          `json:"includeComments"`
+	Subreddits      []string      `json:"subreddits"`
+	MinScore        int           `json:"minScore"`
+	ScoreWindow     time.Duration `json:"scoreWindow"`
+	UpdateInterval  time.Duration `json:"updateInterval"`
+	Schedule        string        `json:"schedule"`
+	FetchStrategy   string        `json:"fetchStrategy"`
+}
+
+type TwitterSourceConfig struct {
+	Username       string        `json:"username"`
+	IncludeReplies bool          `json:"includeReplies"`
+	Instances      []string      `json:"instances"`
+	UpdateInterval time.Duration `json:"updateInterval"`
+	Schedule       string        `json:"schedule"`
+	FetchStrategy  string        `json:"fetchStrategy"`
+}
+
+// QuerySourceConfig is a search query run on every fetch. Seen results are remembered for Retention only,
+// older results are ignored.
+type QuerySourceConfig struct {
+	Provider       string        `json:"provider"`
+	Query          string        `json:"query"`
+	Subreddit      string        `json:"subreddit"`
+	Limit          int           `json:"limit"`
+	Instances      []string      `json:"instances"`
+	UpdateInterval time.Duration `json:"updateInterval"`
+	Schedule       string        `json:"schedule"`
+	FetchStrategy  string        `json:"fetchStrategy"`
+	Retention      time.Duration `json:"retention"`
+}
+
+type ScraperSourceConfig struct {
+	URL            string        `json:"url"`
+	ItemSelector   string        `json:"itemSelector"`
+	TitleSelector  string        `json:"titleSelector"`
+	LinkSelector   string        `json:"linkSelector"`
+	DateSelector   string        `json:"dateSelector"`
+	DateLayout     string        `json:"dateLayout"`
+	UpdateInterval time.Duration `json:"updateInterval"`
+	Schedule       string        `json:"schedule"`
+	FetchStrategy  string        `json:"fetchStrategy"`
+}
+
+const (
+	QueryProviderTwitter    = "twitter"
+	QueryProviderReddit     = "reddit"
+	QueryProviderHackerNews = "hackernews"
+)
+
+type NitterConfig struct {
+	Instances []string `json:"instances"`
+}
+
+type RedditConfig struct {
+	BaseURL   string `json:"baseUrl"`
+	UserAgent string `json:"userAgent"`
 }
 
 type PSQLStorageConfig struct {
 	ConnString     string        `json:"-"`
 	DefaultTimeout time.Duration `json:"defaulTimeout"`
+	MaxConns       int32         `json:"maxConns"`
 }
 
 type TelegramConfig struct {
@@ -29,18 +131,143 @@ type TelegramConfig struct {
 
 type FlareSolverrConfig struct {
 	URL string `json:"url"`
+	// URLs lists instances requests are balanced between, URL is added to them if set.
+	URLs []string `json:"urls"`
+	// EjectAfter consecutive failures exclude the instance until its health check passes,
+	// health of instances is checked every HealthCheckInterval.
+	EjectAfter          int           `json:"ejectAfter"`
+	HealthCheckInterval time.Duration `json:"healthCheckInterval"`
+	// MaxTimeout limits time the browser spends on a page, Timeout limits the whole API request.
+	MaxTimeout time.Duration `json:"maxTimeout"`
+	Timeout    time.Duration `json:"timeout"`
+	// ProxyURL is the proxy used by the browser, its credentials are read from environment variables.
+	ProxyURL      string `json:"proxyUrl"`
+	ProxyUsername string `json:"-"`
+	ProxyPassword string `json:"-"`
+	// FailureThreshold consecutive failures make FlareSolverr unavailable for Cooldown,
+	// then its health is checked before sending requests again.
+	FailureThreshold int           `json:"failureThreshold"`
+	Cooldown         time.Duration `json:"cooldown"`
+	// OnUnavailable is one of FlareSolverrUnavailable* policies applied to items while FlareSolverr is unavailable.
+	OnUnavailable string `json:"onUnavailable"`
+	// Sessions keep solved challenges of a domain for its next pages. A session is replaced after
+	// SessionMaxUses requests and destroyed after SessionIdleTimeout, at most MaxSessions are kept.
+	DisableSessions    bool          `json:"disableSessions"`
+	MaxSessions        int           `json:"maxSessions"`
+	SessionMaxUses     int           `json:"sessionMaxUses"`
+	SessionIdleTimeout time.Duration `json:"sessionIdleTimeout"`
+}
+
+// Policies of handling items which can't be extracted while FlareSolverr is unavailable.
+const (
+	// FlareSolverrUnavailableDefer keeps items queued until FlareSolverr is back.
+	FlareSolverrUnavailableDefer = "defer"
+	// FlareSolverrUnavailableExcerpt reports items with their feed description instead of the article.
+	FlareSolverrUnavailableExcerpt = "excerpt"
+)
+
+type DedupConfig struct {
+	Mode        string        `json:"mode"`
+	MaxDistance int           `json:"maxDistance"`
+	Window      time.Duration `json:"window"`
+}
+
+// Modes of handling near-duplicate items.
+const (
+	DedupModeOff      = "off"
+	DedupModeSuppress = "suppress"
+	DedupModeGroup    = "group"
+)
+
+// RetryConfig controls retries of feed items which failed to be processed.
+type RetryConfig struct {
+	MaxAttempts int           `json:"maxAttempts"`
+	BaseDelay   time.Duration `json:"baseDelay"`
+	MaxDelay    time.Duration `json:"maxDelay"`
+}
+
+type JobQueueConfig struct {
+	// VisibilityTimeout is how long a job is locked by a worker before another one can claim it.
+	VisibilityTimeout time.Duration `json:"visibilityTimeout"`
+	PollInterval      time.Duration `json:"pollInterval"`
+}
+
+// PlannerConfig spreads source runs in time. Every run is delayed randomly up to Jitter
+// and the first runs after startup are spread over Stagger. Zero turns either off.
+type PlannerConfig struct {
+	Jitter  time.Duration `json:"jitter"`
+	Stagger time.Duration `json:"stagger"`
+}
+
+// PolitenessConfig limits requests for item pages to every host, regardless of the fetch strategy.
+type PolitenessConfig struct {
+	// Rate is the number of requests per second to a host, Burst is how many of them may go at once.
+	Rate        float64 `json:"rate"`
+	Burst       int     `json:"burst"`
+	Concurrency int     `json:"concurrency"`
+	// MaxWait is how long a job waits for the host turn before it's retried later.
+	MaxWait      time.Duration `json:"maxWait"`
+	IgnoreRobots bool          `json:"ignoreRobots"`
+	RobotsTTL    time.Duration `json:"robotsTtl"`
 }
 
 type Config struct {
-	RSSSources   map[string]RSSSourceConfig `json:"rssSources"`
-	Telegram     TelegramConfig             `json:"telegram"`
-	PSQLStorage  PSQLStorageConfig          `json:"psqlStorage"`
-	FlareSolverr FlareSolverrConfig         `json:"flareSolverr"`
+	RSSSources     map[string]RSSSourceConfig        `json:"rssSources"`
+	RedditSources  map[string]RedditSourceConfig     `json:"redditSources"`
+	RedditUsers    map[string]RedditUserSourceConfig `json:"redditUsers"`
+	TwitterSources map[string]TwitterSourceConfig    `json:"twitterSources"`
+	QuerySources   map[string]QuerySourceConfig      `json:"querySources"`
+	ScraperSources map[string]ScraperSourceConfig    `json:"scraperSources"`
+	Reddit         RedditConfig                      `json:"reddit"`
+	Nitter         NitterConfig                      `json:"nitter"`
+	Telegram       TelegramConfig                    `json:"telegram"`
+	PSQLStorage    PSQLStorageConfig                 `json:"psqlStorage"`
+	FlareSolverr   FlareSolverrConfig                `json:"flareSolverr"`
+	Dedup          DedupConfig                       `json:"dedup"`
+	Retry          RetryConfig                       `json:"retry"`
+	JobQueue       JobQueueConfig                    `json:"jobQueue"`
+	Planner        PlannerConfig                     `json:"planner"`
+	Politeness     PolitenessConfig                  `json:"politeness"`
+	Workers        int                               `json:"workers"`
 }
 
 var (
-	DefaultRSSUpdateInterval = 5 * time.Minute
-	DefaultPSQLTimeout       = 5 * time.Second
+	DefaultRSSUpdateInterval      = 5 * time.Minute
+	DefaultRSSMinInterval         = 5 * time.Minute
+	DefaultRSSMaxInterval         = 24 * time.Hour
+	DefaultRedditUpdateInterval   = 15 * time.Minute
+	DefaultRedditSort             = "new"
+	DefaultRedditLimit            = 25
+	DefaultRedditScoreWindow      = 48 * time.Hour
+	DefaultTwitterUpdateInterval  = 10 * time.Minute
+	DefaultQueryUpdateInterval    = 30 * time.Minute
+	DefaultQueryLimit             = 50
+	DefaultQueryRetention         = 30 * 24 * time.Hour
+	DefaultScraperUpdateInterval  = 30 * time.Minute
+	DefaultPSQLTimeout            = 5 * time.Second
+	DefaultDedupMaxDistance       = 3
+	DefaultDedupWindow            = 72 * time.Hour
+	DefaultWorkers                = 4
+	DefaultRetryMaxAttempts       = 8
+	DefaultRetryBaseDelay         = time.Minute
+	DefaultRetryMaxDelay          = 6 * time.Hour
+	DefaultJobVisibilityTimeout   = 10 * time.Minute
+	DefaultJobPollInterval        = 2 * time.Second
+	DefaultPlannerJitter          = 30 * time.Second
+	DefaultPlannerStagger         = time.Minute
+	DefaultPolitenessRate         = 0.5
+	DefaultPolitenessBurst        = 1
+	DefaultPolitenessConcurrency  = 2
+	DefaultPolitenessMaxWait      = time.Minute
+	DefaultRobotsTTL              = 24 * time.Hour
+	DefaultFlareSolverrThreshold  = 5
+	DefaultFlareSolverrMaxTimeout = time.Minute
+	DefaultMaxSessions            = 5
+	DefaultSessionMaxUses         = 100
+	DefaultSessionIdleTimeout     = 10 * time.Minute
+	DefaultInstanceEjectAfter     = 3
+	DefaultHealthCheckInterval    = 30 * time.Second
+	DefaultFlareSolverrCooldown   = time.Minute
 )
 
 func Load(path string, env EnvVarProvider) (*Config, error) {
@@ -50,7 +277,12 @@ func Load(path string, env EnvVarProvider) (*Config, error) {
 	}
 
 	d := json.NewDecoder(f)
-	var cfg Config
+	// zero max distance matches equal fingerprints only and zero jitter or stagger turns it off,
+	// so their defaults are set before decoding
+	cfg := Config{
+		Dedup:   DedupConfig{MaxDistance: DefaultDedupMaxDistance},
+		Planner: PlannerConfig{Jitter: DefaultPlannerJitter, Stagger: DefaultPlannerStagger},
+	}
 	err = d.Decode(&cfg)
 
 	if err != nil {
@@ -68,6 +300,138 @@ func Load(path string, env EnvVarProvider) (*Config, error) {
 		cfg.PSQLStorage.DefaultTimeout = DefaultPSQLTimeout
 	}
 
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+
+	switch cfg.Dedup.Mode {
+	case "":
+		cfg.Dedup.Mode = DedupModeGroup
+	case DedupModeOff, DedupModeSuppress, DedupModeGroup:
+	default:
+		return nil, fmt.Errorf("unsupported dedup mode %q", cfg.Dedup.Mode)
+	}
+	if cfg.Dedup.MaxDistance < 0 || cfg.Dedup.MaxDistance > 64 {
+		return nil, fmt.Errorf("dedup max distance %d is out of range [0, 64]", cfg.Dedup.MaxDistance)
+	}
+	if cfg.Dedup.Window == 0 {
+		cfg.Dedup.Window = DefaultDedupWindow
+	}
+
+	if cfg.Retry.MaxAttempts == 0 {
+		cfg.Retry.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if cfg.Retry.BaseDelay == 0 {
+		cfg.Retry.BaseDelay = DefaultRetryBaseDelay
+	}
+	if cfg.Retry.MaxDelay == 0 {
+		cfg.Retry.MaxDelay = DefaultRetryMaxDelay
+	}
+
+	if cfg.JobQueue.VisibilityTimeout == 0 {
+		cfg.JobQueue.VisibilityTimeout = DefaultJobVisibilityTimeout
+	}
+	if cfg.JobQueue.PollInterval == 0 {
+		cfg.JobQueue.PollInterval = DefaultJobPollInterval
+	}
+
+	if cfg.Planner.Jitter < 0 || cfg.Planner.Stagger < 0 {
+		return nil, errors.New("planner jitter and stagger should not be negative")
+	}
+
+	if cfg.FlareSolverr.URL != "" && !slices.Contains(cfg.FlareSolverr.URLs, cfg.FlareSolverr.URL) {
+		cfg.FlareSolverr.URLs = append([]string{cfg.FlareSolverr.URL}, cfg.FlareSolverr.URLs...)
+	}
+	if cfg.FlareSolverr.EjectAfter == 0 {
+		cfg.FlareSolverr.EjectAfter = DefaultInstanceEjectAfter
+	}
+	if cfg.FlareSolverr.HealthCheckInterval == 0 {
+		cfg.FlareSolverr.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	if cfg.FlareSolverr.MaxTimeout == 0 {
+		cfg.FlareSolverr.MaxTimeout = DefaultFlareSolverrMaxTimeout
+	}
+	// the API request lasts longer than the browser work, the margin covers browser startup
+	if cfg.FlareSolverr.Timeout == 0 {
+		cfg.FlareSolverr.Timeout = cfg.FlareSolverr.MaxTimeout + 30*time.Second
+	}
+	if cfg.FlareSolverr.Timeout <= cfg.FlareSolverr.MaxTimeout {
+		return nil, errors.New("flareSolverr timeout should exceed maxTimeout")
+	}
+	if cfg.FlareSolverr.MaxSessions == 0 {
+		cfg.FlareSolverr.MaxSessions = DefaultMaxSessions
+	}
+	if cfg.FlareSolverr.SessionMaxUses == 0 {
+		cfg.FlareSolverr.SessionMaxUses = DefaultSessionMaxUses
+	}
+	if cfg.FlareSolverr.SessionIdleTimeout == 0 {
+		cfg.FlareSolverr.SessionIdleTimeout = DefaultSessionIdleTimeout
+	}
+	cfg.FlareSolverr.ProxyUsername, _ = env.LookupEnv("IC_FLARESOLVERR_PROXY_USERNAME")
+	cfg.FlareSolverr.ProxyPassword, _ = env.LookupEnv("IC_FLARESOLVERR_PROXY_PASSWORD")
+
+	if cfg.FlareSolverr.FailureThreshold == 0 {
+		cfg.FlareSolverr.FailureThreshold = DefaultFlareSolverrThreshold
+	}
+	if cfg.FlareSolverr.Cooldown == 0 {
+		cfg.FlareSolverr.Cooldown = DefaultFlareSolverrCooldown
+	}
+	switch cfg.FlareSolverr.OnUnavailable {
+	case "":
+		cfg.FlareSolverr.OnUnavailable = FlareSolverrUnavailableDefer
+	case FlareSolverrUnavailableDefer, FlareSolverrUnavailableExcerpt:
+	default:
+		return nil, fmt.Errorf("unsupported flareSolverr onUnavailable %q", cfg.FlareSolverr.OnUnavailable)
+	}
+
+	if cfg.Politeness.Rate < 0 || cfg.Politeness.Burst < 0 || cfg.Politeness.Concurrency < 0 {
+		return nil, errors.New("politeness rate, burst and concurrency should not be negative")
+	}
+	if cfg.Politeness.Rate == 0 {
+		cfg.Politeness.Rate = DefaultPolitenessRate
+	}
+	if cfg.Politeness.Burst == 0 {
+		cfg.Politeness.Burst = DefaultPolitenessBurst
+	}
+	if cfg.Politeness.Concurrency == 0 {
+		cfg.Politeness.Concurrency = DefaultPolitenessConcurrency
+	}
+	if cfg.Politeness.MaxWait == 0 {
+		cfg.Politeness.MaxWait = DefaultPolitenessMaxWait
+	}
+	if cfg.Politeness.RobotsTTL == 0 {
+		cfg.Politeness.RobotsTTL = DefaultRobotsTTL
+	}
+
+	for name, c := range cfg.RSSSources {
+		if c.FeedURL == "" && c.SiteURL == "" {
+			return nil, fmt.Errorf("rss source %s: feedUrl or siteUrl should be set", name)
+		}
+		switch c.OnUpdate {
+		case "":
+			c.OnUpdate = ItemUpdateIgnore
+			cfg.RSSSources[name] = c
+		case ItemUpdateIgnore, ItemUpdateReport, ItemUpdateEdit:
+		default:
+			return nil, fmt.Errorf("rss source %s: unsupported onUpdate %q", name, c.OnUpdate)
+		}
+		if c.Adaptive {
+			if c.Schedule != "" {
+				return nil, fmt.Errorf("rss source %s: schedule can't be used with adaptive polling", name)
+			}
+			if c.MinInterval == 0 {
+				c.MinInterval = DefaultRSSMinInterval
+			}
+			if c.MaxInterval == 0 {
+				c.MaxInterval = DefaultRSSMaxInterval
+			}
+			if c.MinInterval > c.MaxInterval {
+				return nil, fmt.Errorf("rss source %s: minInterval should not exceed maxInterval", name)
+			}
+			cfg.RSSSources[name] = c
+		}
+	}
+
 	for i := range cfg.RSSSources {
 		if cfg.RSSSources[i].UpdateInterval == 0 {
 			c := cfg.RSSSources[i]
@@ -78,5 +442,217 @@ func Load(path string, env EnvVarProvider) (*Config, error) {
 		}
 	}
 
+	for name, c := range cfg.RedditSources {
+		if c.Subreddit == "" {
+			return nil, fmt.Errorf("reddit source %s: subreddit should be set", name)
+		}
+		switch c.Sort {
+		case "":
+			c.Sort = DefaultRedditSort
+		case "new", "hot", "top":
+		default:
+			return nil, fmt.Errorf("reddit source %s: unsupported sort %q", name, c.Sort)
+		}
+		if c.Limit == 0 {
+			c.Limit = DefaultRedditLimit
+		}
+		if c.UpdateInterval == 0 {
+			c.UpdateInterval = DefaultRedditUpdateInterval
+		}
+		cfg.RedditSources[name] = c
+	}
+
+	for name, c := range cfg.RedditUsers {
+		if c.Username == "" {
+			return nil, fmt.Errorf("reddit user source %s: username should be set", name)
+		}
+		if c.UpdateInterval == 0 {
+			c.UpdateInterval = DefaultRedditUpdateInterval
+		}
+		if c.ScoreWindow == 0 {
+			c.ScoreWindow = DefaultRedditScoreWindow
+		}
+		cfg.RedditUsers[name] = c
+	}
+
+	for name, c := range cfg.TwitterSources {
+		if c.Username == "" {
+			return nil, fmt.Errorf("twitter source %s: username should be set", name)
+		}
+		if len(c.Instances) == 0 {
+			c.Instances = cfg.Nitter.Instances
+		}
+		if len(c.Instances) == 0 {
+			return nil, fmt.Errorf("twitter source %s: no Nitter instances configured", name)
+		}
+		if c.UpdateInterval == 0 {
+			c.UpdateInterval = DefaultTwitterUpdateInterval
+		}
+		cfg.TwitterSources[name] = c
+	}
+
+	for name, c := range cfg.QuerySources {
+		if c.Query == "" {
+			return nil, fmt.Errorf("query source %s: query should be set", name)
+		}
+		switch c.Provider {
+		case QueryProviderTwitter:
+			if len(c.Instances) == 0 {
+				c.Instances = cfg.Nitter.Instances
+			}
+			if len(c.Instances) == 0 {
+				return nil, fmt.Errorf("query source %s: no Nitter instances configured", name)
+			}
+		case QueryProviderReddit, QueryProviderHackerNews:
+		default:
+			return nil, fmt.Errorf("query source %s: unsupported provider %q", name, c.Provider)
+		}
+		if c.Limit == 0 {
+			c.Limit = DefaultQueryLimit
+		}
+		if c.UpdateInterval == 0 {
+			c.UpdateInterval = DefaultQueryUpdateInterval
+		}
+		if c.Retention == 0 {
+			c.Retention = DefaultQueryRetention
+		}
+		cfg.QuerySources[name] = c
+	}
+
+	for name, c := range cfg.ScraperSources {
+		if c.URL == "" || c.ItemSelector == "" {
+			return nil, fmt.Errorf("scraper source %s: url and itemSelector should be set", name)
+		}
+		if c.UpdateInterval == 0 {
+			c.UpdateInterval = DefaultScraperUpdateInterval
+			cfg.ScraperSources[name] = c
+		}
+	}
+
+	if err := cfg.validateSourceNames(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validateSchedules(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validateFetchStrategies(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
+
+// validateSourceNames checks that source names are unique across all source kinds,
+// since they share the same namespace in storage.
+func (c *Config) validateSourceNames() error {
+	seen := make(map[string]struct{}, len(c.RSSSources)+len(c.RedditSources)+len(c.RedditUsers)+len(c.TwitterSources)+len(c.QuerySources)+len(c.ScraperSources))
+	add := func(name string) error {
+		if _, has := seen[name]; has {
+			return fmt.Errorf("source name %s is used more than once", name)
+		}
+		seen[name] = struct{}{}
+		return nil
+	}
+
+	for name := range c.RSSSources {
+		if err := add(name); err != nil {
+			return err
+		}
+	}
+	for name := range c.RedditSources {
+		if err := add(name); err != nil {
+			return err
+		}
+	}
+	for name := range c.RedditUsers {
+		if err := add(name); err != nil {
+			return err
+		}
+	}
+	for name := range c.TwitterSources {
+		if err := add(name); err != nil {
+			return err
+		}
+	}
+	for name := range c.QuerySources {
+		if err := add(name); err != nil {
+			return err
+		}
+	}
+	for name := range c.ScraperSources {
+		if err := add(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSchedules checks that source schedules, which replace update intervals when set, are valid cron specs.
+func (c *Config) validateSchedules() error {
+	specs := make(map[string]string)
+	for name, src := range c.RSSSources {
+		specs[name] = src.Schedule
+	}
+	for name, src := range c.RedditSources {
+		specs[name] = src.Schedule
+	}
+	for name, src := range c.RedditUsers {
+		specs[name] = src.Schedule
+	}
+	for name, src := range c.TwitterSources {
+		specs[name] = src.Schedule
+	}
+	for name, src := range c.QuerySources {
+		specs[name] = src.Schedule
+	}
+	for name, src := range c.ScraperSources {
+		specs[name] = src.Schedule
+	}
+
+	for name, spec := range specs {
+		if spec == "" {
+			continue
+		}
+		if _, err := planner.Parse(spec); err != nil {
+			return fmt.Errorf("source %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateFetchStrategies checks source fetch strategies. Empty strategy means FetchStrategyAuto.
+func (c *Config) validateFetchStrategies() error {
+	strategies := make(map[string]string)
+	for name, src := range c.RSSSources {
+		strategies[name] = src.FetchStrategy
+	}
+	for name, src := range c.RedditSources {
+		strategies[name] = src.FetchStrategy
+	}
+	for name, src := range c.RedditUsers {
+		strategies[name] = src.FetchStrategy
+	}
+	for name, src := range c.TwitterSources {
+		strategies[name] = src.FetchStrategy
+	}
+	for name, src := range c.QuerySources {
+		strategies[name] = src.FetchStrategy
+	}
+	for name, src := range c.ScraperSources {
+		strategies[name] = src.FetchStrategy
+	}
+
+	for name, strategy := range strategies {
+		switch strategy {
+		case "", FetchStrategyAuto, FetchStrategyDirect, FetchStrategyFlareSolverr:
+		default:
+			return fmt.Errorf("source %s: unsupported fetchStrategy %q", name, strategy)
+		}
+	}
+
+	return nil
+}
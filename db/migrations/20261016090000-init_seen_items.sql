@@ -0,0 +1,12 @@
+
+-- +migrate Up
+CREATE TABLE seen_items (
+    source_id INT REFERENCES sources (source_id) NOT NULL,
+    item_key TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    UNIQUE (source_id, item_key)
+);
+
+-- +migrate Down
+DROP TABLE seen_items;
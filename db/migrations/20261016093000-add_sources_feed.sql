@@ -0,0 +1,12 @@
+
+-- +migrate Up
+ALTER TABLE sources
+ADD COLUMN feed_url TEXT,
+ADD COLUMN update_interval INTERVAL,
+ADD COLUMN category TEXT;
+
+-- +migrate Down
+ALTER TABLE sources
+DROP COLUMN feed_url,
+DROP COLUMN update_interval,
+DROP COLUMN category;
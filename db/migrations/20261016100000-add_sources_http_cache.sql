@@ -0,0 +1,12 @@
+
+-- +migrate Up
+ALTER TABLE sources
+ADD COLUMN http_etag TEXT,
+ADD COLUMN http_last_modified TEXT,
+ADD COLUMN fetch_not_before TIMESTAMPTZ;
+
+-- +migrate Down
+ALTER TABLE sources
+DROP COLUMN http_etag,
+DROP COLUMN http_last_modified,
+DROP COLUMN fetch_not_before;
@@ -0,0 +1,16 @@
+
+-- +migrate Up
+ALTER TABLE seen_items
+ADD COLUMN item_version TEXT NOT NULL DEFAULT '',
+ADD COLUMN source_item_id INT REFERENCES sources_items (source_item_id);
+
+ALTER TABLE sources_items
+ADD COLUMN telegram_message_id INT;
+
+-- +migrate Down
+ALTER TABLE sources_items
+DROP COLUMN telegram_message_id;
+
+ALTER TABLE seen_items
+DROP COLUMN item_version,
+DROP COLUMN source_item_id;
@@ -0,0 +1,22 @@
+
+-- +migrate Up
+ALTER TABLE sources_items
+ADD COLUMN canonical_url TEXT;
+
+CREATE INDEX sources_items_canonical_url_idx ON sources_items (canonical_url);
+
+CREATE TABLE sources_items_links (
+    source_item_id INT REFERENCES sources_items (source_item_id) NOT NULL,
+    source_id INT REFERENCES sources (source_id) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    UNIQUE (source_item_id, source_id)
+);
+
+-- +migrate Down
+DROP TABLE sources_items_links;
+
+DROP INDEX sources_items_canonical_url_idx;
+
+ALTER TABLE sources_items
+DROP COLUMN canonical_url;
@@ -0,0 +1,14 @@
+
+-- +migrate Up
+ALTER TABLE sources_items
+ADD COLUMN fingerprint BIGINT;
+
+CREATE INDEX sources_items_fingerprint_created_at_idx
+ON sources_items (created_at) INCLUDE (fingerprint)
+WHERE fingerprint IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX sources_items_fingerprint_created_at_idx;
+
+ALTER TABLE sources_items
+DROP COLUMN fingerprint;
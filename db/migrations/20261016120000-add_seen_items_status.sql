@@ -0,0 +1,35 @@
+
+-- +migrate Up
+CREATE TYPE seen_items_status AS ENUM (
+    'pending', 'extracted', 'reported', 'failed'
+);
+
+ALTER TABLE seen_items
+ADD COLUMN status SEEN_ITEMS_STATUS NOT NULL DEFAULT 'reported',
+ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+ADD COLUMN last_error TEXT,
+ADD COLUMN next_attempt_at TIMESTAMPTZ,
+ADD COLUMN telegram_message_id INT,
+ADD COLUMN item JSONB;
+
+UPDATE seen_items
+SET telegram_message_id = sources_items.telegram_message_id
+FROM sources_items
+WHERE seen_items.source_item_id = sources_items.source_item_id;
+
+CREATE INDEX seen_items_due_idx
+ON seen_items (source_id, next_attempt_at)
+WHERE status != 'reported';
+
+-- +migrate Down
+DROP INDEX seen_items_due_idx;
+
+ALTER TABLE seen_items
+DROP COLUMN status,
+DROP COLUMN attempts,
+DROP COLUMN last_error,
+DROP COLUMN next_attempt_at,
+DROP COLUMN telegram_message_id,
+DROP COLUMN item;
+
+DROP TYPE SEEN_ITEMS_STATUS;
@@ -0,0 +1,76 @@
+
+-- +migrate Up
+CREATE TYPE jobs_type AS ENUM (
+    'fetch_source', 'extract_item', 'report_item'
+);
+
+CREATE TYPE jobs_status AS ENUM (
+    'queued', 'running', 'dead'
+);
+
+CREATE TABLE jobs (
+    job_id BIGSERIAL PRIMARY KEY,
+    type JOBS_TYPE NOT NULL,
+    source_id INT REFERENCES sources (source_id) NOT NULL,
+    item_key TEXT NOT NULL DEFAULT '',
+    status JOBS_STATUS NOT NULL DEFAULT 'queued',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    last_error TEXT,
+    run_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX jobs_queued_idx
+ON jobs (type, source_id, item_key)
+WHERE status = 'queued';
+
+CREATE INDEX jobs_run_at_idx
+ON jobs (run_at)
+WHERE status != 'dead';
+
+INSERT INTO jobs (
+    type, source_id, item_key, max_attempts, run_at, created_at, updated_at
+)
+SELECT
+    CASE
+        WHEN source_item_id IS NULL THEN 'extract_item'
+        ELSE 'report_item'
+    END::JOBS_TYPE,
+    source_id,
+    item_key,
+    8,
+    next_attempt_at,
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP
+FROM seen_items
+WHERE status != 'reported' AND next_attempt_at IS NOT NULL;
+
+DROP INDEX seen_items_due_idx;
+
+ALTER TABLE seen_items
+DROP COLUMN next_attempt_at;
+
+-- +migrate Down
+ALTER TABLE seen_items
+ADD COLUMN next_attempt_at TIMESTAMPTZ;
+
+UPDATE seen_items
+SET next_attempt_at = jobs.run_at
+FROM jobs
+WHERE
+    jobs.source_id = seen_items.source_id
+    AND jobs.item_key = seen_items.item_key
+    AND jobs.status != 'dead';
+
+CREATE INDEX seen_items_due_idx
+ON seen_items (source_id, next_attempt_at)
+WHERE status != 'reported';
+
+DROP TABLE jobs;
+
+DROP TYPE JOBS_STATUS;
+
+DROP TYPE JOBS_TYPE;
@@ -0,0 +1,7 @@
+
+-- +migrate Up notransaction
+ALTER TYPE jobs_status ADD VALUE 'skipped';
+
+-- +migrate Down
+DELETE FROM jobs
+WHERE status = 'skipped';
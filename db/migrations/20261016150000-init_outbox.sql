@@ -0,0 +1,79 @@
+
+-- +migrate Up
+CREATE TYPE outbox_kind AS ENUM (
+    'report', 'edit', 'coverage'
+);
+
+CREATE TYPE outbox_status AS ENUM (
+    'pending', 'sent'
+);
+
+CREATE TABLE outbox (
+    outbox_id BIGSERIAL PRIMARY KEY,
+    kind OUTBOX_KIND NOT NULL,
+    status OUTBOX_STATUS NOT NULL DEFAULT 'pending',
+    source_id INT REFERENCES sources (source_id),
+    item_key TEXT NOT NULL DEFAULT '',
+    source_item_id INT REFERENCES sources_items (source_item_id) NOT NULL,
+    telegram_message_id INT,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    run_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX outbox_pending_idx
+ON outbox (outbox_id)
+WHERE status = 'pending';
+
+-- reports are sent from outbox now, report_item jobs aren't used anymore
+INSERT INTO outbox (
+    kind, source_id, item_key, source_item_id, run_at, created_at
+)
+SELECT
+    'report',
+    jobs.source_id,
+    jobs.item_key,
+    seen_items.source_item_id,
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP
+FROM jobs
+INNER JOIN seen_items
+    ON
+        jobs.source_id = seen_items.source_id
+        AND jobs.item_key = seen_items.item_key
+WHERE
+    jobs.type = 'report_item'
+    AND jobs.status IN ('queued', 'running')
+    AND seen_items.source_item_id IS NOT NULL;
+
+DELETE FROM jobs
+WHERE type = 'report_item' AND status IN ('queued', 'running');
+
+UPDATE jobs
+SET status = 'skipped'
+WHERE type = 'report_item' AND status = 'dead';
+
+-- +migrate Down
+INSERT INTO jobs (
+    type, source_id, item_key, max_attempts, run_at, created_at, updated_at
+)
+SELECT
+    'report_item',
+    source_id,
+    item_key,
+    8,
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP
+FROM outbox
+WHERE status = 'pending' AND kind IN ('report', 'edit')
+ON CONFLICT (type, source_id, item_key) WHERE status = 'queued' DO NOTHING;
+
+DROP TABLE outbox;
+
+DROP TYPE OUTBOX_STATUS;
+
+DROP TYPE OUTBOX_KIND;
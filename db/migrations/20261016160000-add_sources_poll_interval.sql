@@ -0,0 +1,8 @@
+
+-- +migrate Up
+ALTER TABLE sources
+ADD COLUMN poll_interval INTERVAL;
+
+-- +migrate Down
+ALTER TABLE sources
+DROP COLUMN poll_interval;
@@ -0,0 +1,8 @@
+
+-- +migrate Up
+ALTER TABLE sources
+ADD COLUMN next_run_at TIMESTAMPTZ;
+
+-- +migrate Down
+ALTER TABLE sources
+DROP COLUMN next_run_at;
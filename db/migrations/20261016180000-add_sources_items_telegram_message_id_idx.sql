@@ -0,0 +1,7 @@
+
+-- +migrate Up
+CREATE INDEX sources_items_telegram_message_id_idx ON sources_items (telegram_message_id)
+WHERE telegram_message_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX sources_items_telegram_message_id_idx;
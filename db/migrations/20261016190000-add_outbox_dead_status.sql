@@ -0,0 +1,8 @@
+
+-- +migrate Up notransaction
+ALTER TYPE outbox_status ADD VALUE 'dead';
+
+-- +migrate Down
+UPDATE outbox
+SET status = 'sent'
+WHERE status = 'dead';
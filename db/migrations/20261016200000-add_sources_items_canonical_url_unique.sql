@@ -0,0 +1,26 @@
+
+-- +migrate Up
+ALTER TABLE sources_items
+ADD COLUMN updated BOOLEAN NOT NULL DEFAULT false;
+
+-- versions of updated items share canonical URL with the first version
+UPDATE sources_items
+SET updated = true
+WHERE
+    canonical_url IS NOT NULL
+    AND source_item_id NOT IN (
+        SELECT min(first.source_item_id)
+        FROM sources_items AS first
+        WHERE first.canonical_url IS NOT NULL
+        GROUP BY first.canonical_url
+    );
+
+CREATE UNIQUE INDEX sources_items_canonical_url_key
+ON sources_items (canonical_url)
+WHERE canonical_url IS NOT NULL AND NOT updated;
+
+-- +migrate Down
+DROP INDEX sources_items_canonical_url_key;
+
+ALTER TABLE sources_items
+DROP COLUMN updated;
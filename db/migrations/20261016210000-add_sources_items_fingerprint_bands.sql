@@ -0,0 +1,35 @@
+
+-- +migrate Up
+ALTER TABLE sources_items
+ADD COLUMN fingerprint_band_0 INT GENERATED ALWAYS AS ((fingerprint & 65535)::INT) STORED,
+ADD COLUMN fingerprint_band_1 INT GENERATED ALWAYS AS (((fingerprint >> 16) & 65535)::INT) STORED,
+ADD COLUMN fingerprint_band_2 INT GENERATED ALWAYS AS (((fingerprint >> 32) & 65535)::INT) STORED,
+ADD COLUMN fingerprint_band_3 INT GENERATED ALWAYS AS (((fingerprint >> 48) & 65535)::INT) STORED;
+
+CREATE INDEX sources_items_fingerprint_band_0_idx
+ON sources_items (fingerprint_band_0, created_at)
+WHERE fingerprint IS NOT NULL;
+
+CREATE INDEX sources_items_fingerprint_band_1_idx
+ON sources_items (fingerprint_band_1, created_at)
+WHERE fingerprint IS NOT NULL;
+
+CREATE INDEX sources_items_fingerprint_band_2_idx
+ON sources_items (fingerprint_band_2, created_at)
+WHERE fingerprint IS NOT NULL;
+
+CREATE INDEX sources_items_fingerprint_band_3_idx
+ON sources_items (fingerprint_band_3, created_at)
+WHERE fingerprint IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX sources_items_fingerprint_band_3_idx;
+DROP INDEX sources_items_fingerprint_band_2_idx;
+DROP INDEX sources_items_fingerprint_band_1_idx;
+DROP INDEX sources_items_fingerprint_band_0_idx;
+
+ALTER TABLE sources_items
+DROP COLUMN fingerprint_band_0,
+DROP COLUMN fingerprint_band_1,
+DROP COLUMN fingerprint_band_2,
+DROP COLUMN fingerprint_band_3;
@@ -0,0 +1,8 @@
+
+-- +migrate Up notransaction
+ALTER TYPE outbox_status ADD VALUE 'skipped';
+
+-- +migrate Down
+UPDATE outbox
+SET status = 'sent'
+WHERE status = 'skipped';
@@ -0,0 +1,10 @@
+
+-- +migrate Up
+ALTER TABLE sources
+ADD COLUMN site_url TEXT,
+ADD COLUMN discovered_feed_url TEXT;
+
+-- +migrate Down
+ALTER TABLE sources
+DROP COLUMN site_url,
+DROP COLUMN discovered_feed_url;
@@ -0,0 +1,137 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (
+    type,
+    source_id,
+    item_key,
+    max_attempts,
+    run_at,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+) ON CONFLICT (type, source_id, item_key) WHERE status = 'queued' DO NOTHING
+RETURNING job_id;
+
+-- name: ClaimJob :one
+UPDATE jobs
+SET
+    status = 'running',
+    attempts = jobs.attempts + 1,
+    locked_until = CURRENT_TIMESTAMP + sqlc.arg(visibility_timeout)::INTERVAL,
+    updated_at = CURRENT_TIMESTAMP
+FROM sources
+WHERE
+    sources.source_id = jobs.source_id
+    AND jobs.job_id = (
+        SELECT due.job_id
+        FROM jobs AS due
+        WHERE
+            (
+                (due.status = 'queued' AND due.run_at <= CURRENT_TIMESTAMP)
+                OR (due.status = 'running' AND due.locked_until < CURRENT_TIMESTAMP)
+            )
+            AND NOT EXISTS (
+                SELECT 1
+                FROM jobs AS running
+                WHERE
+                    running.status = 'running'
+                    AND running.locked_until >= CURRENT_TIMESTAMP
+                    AND running.type = due.type
+                    AND running.source_id = due.source_id
+                    AND running.item_key = due.item_key
+            )
+        ORDER BY due.run_at
+        LIMIT 1
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING
+    jobs.job_id,
+    jobs.type,
+    sources.name,
+    jobs.item_key,
+    jobs.attempts,
+    jobs.max_attempts;
+
+-- name: DeleteJob :exec
+-- Jobs are matched by attempts too, so a worker which exceeded visibility timeout
+-- can't change the job reclaimed by another worker.
+DELETE FROM jobs
+WHERE job_id = $1 AND attempts = $2;
+
+-- name: RetryJob :exec
+UPDATE jobs
+SET
+    status = 'queued',
+    last_error = $3,
+    run_at = $4,
+    locked_until = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE job_id = $1 AND attempts = $2;
+
+-- name: DeferJob :exec
+-- Deferred jobs didn't fail, so their attempt isn't counted.
+UPDATE jobs
+SET
+    status = 'queued',
+    attempts = jobs.attempts - 1,
+    last_error = $3,
+    run_at = $4,
+    locked_until = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE job_id = $1 AND attempts = $2;
+
+-- name: BuryJob :exec
+UPDATE jobs
+SET
+    status = 'dead',
+    last_error = $3,
+    locked_until = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE job_id = $1 AND attempts = $2;
+
+-- name: ListDeadJobs :many
+SELECT
+    jobs.job_id,
+    jobs.type,
+    sources.name,
+    jobs.item_key,
+    jobs.attempts,
+    jobs.last_error,
+    jobs.updated_at,
+    COALESCE(seen_items.item ->> 'Link', '')::TEXT AS link
+FROM jobs
+INNER JOIN sources ON jobs.source_id = sources.source_id
+LEFT JOIN seen_items
+    ON
+        jobs.source_id = seen_items.source_id
+        AND jobs.item_key = seen_items.item_key
+WHERE jobs.status = 'dead'
+ORDER BY jobs.updated_at DESC
+LIMIT $1;
+
+-- name: RequeueDeadJob :execrows
+UPDATE jobs
+SET
+    status = 'queued',
+    attempts = 0,
+    run_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE job_id = $1 AND status = 'dead';
+
+-- name: DeleteDeadJob :exec
+DELETE FROM jobs
+WHERE job_id = $1 AND status = 'dead';
+
+-- name: SkipDeadJob :execrows
+UPDATE jobs
+SET
+    status = 'skipped',
+    updated_at = CURRENT_TIMESTAMP
+WHERE job_id = $1 AND status = 'dead';
+
+-- name: SkipAllDeadJobs :execrows
+UPDATE jobs
+SET
+    status = 'skipped',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status = 'dead';
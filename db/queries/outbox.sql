@@ -0,0 +1,126 @@
+-- name: EnqueueOutboxMessage :exec
+INSERT INTO outbox (
+    kind,
+    source_id,
+    item_key,
+    source_item_id,
+    telegram_message_id,
+    run_at,
+    created_at
+) VALUES (
+    $1,
+    (SELECT sources.source_id FROM sources WHERE sources.name = $2),
+    $3,
+    $4,
+    $5,
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP
+);
+
+-- name: ClaimOutboxMessage :one
+UPDATE outbox
+SET
+    attempts = outbox.attempts + 1,
+    locked_until = CURRENT_TIMESTAMP + sqlc.arg(visibility_timeout)::INTERVAL
+WHERE outbox.outbox_id = (
+    SELECT due.outbox_id
+    FROM outbox AS due
+    WHERE
+        due.status = 'pending'
+        AND due.run_at <= CURRENT_TIMESTAMP
+        AND (due.locked_until IS NULL OR due.locked_until < CURRENT_TIMESTAMP)
+    ORDER BY due.outbox_id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING
+    outbox.outbox_id,
+    outbox.kind,
+    COALESCE(
+        (SELECT sources.name FROM sources WHERE sources.source_id = outbox.source_id), ''
+    )::TEXT AS source_name,
+    outbox.item_key,
+    outbox.source_item_id,
+    outbox.telegram_message_id,
+    outbox.attempts;
+
+-- name: SetOutboxTelegramMessageID :exec
+UPDATE outbox
+SET telegram_message_id = $3
+WHERE outbox_id = $1 AND attempts = $2;
+
+-- name: CompleteOutboxMessage :exec
+UPDATE outbox
+SET
+    status = 'sent',
+    locked_until = NULL,
+    sent_at = CURRENT_TIMESTAMP
+WHERE outbox_id = $1 AND attempts = $2;
+
+-- name: RetryOutboxMessage :exec
+UPDATE outbox
+SET
+    last_error = $3,
+    run_at = $4,
+    locked_until = NULL
+WHERE outbox_id = $1 AND attempts = $2;
+
+-- name: DeferOutboxMessage :exec
+UPDATE outbox
+SET
+    attempts = outbox.attempts - 1,
+    last_error = $3,
+    run_at = $4,
+    locked_until = NULL
+WHERE outbox_id = $1 AND attempts = $2;
+
+-- name: BuryOutboxMessage :exec
+UPDATE outbox
+SET
+    status = 'dead',
+    last_error = $3,
+    run_at = CURRENT_TIMESTAMP,
+    locked_until = NULL
+WHERE outbox_id = $1 AND attempts = $2;
+
+-- name: ListDeadOutboxMessages :many
+SELECT
+    outbox.outbox_id,
+    outbox.kind,
+    COALESCE(sources.name, '')::TEXT AS source_name,
+    outbox.attempts,
+    outbox.last_error,
+    outbox.run_at,
+    COALESCE(sources_items.url, '')::TEXT AS link
+FROM outbox
+INNER JOIN sources_items ON outbox.source_item_id = sources_items.source_item_id
+LEFT JOIN sources ON outbox.source_id = sources.source_id
+WHERE outbox.status = 'dead'
+ORDER BY outbox.run_at DESC
+LIMIT $1;
+
+-- name: RequeueDeadOutboxMessage :execrows
+UPDATE outbox
+SET
+    status = 'pending',
+    attempts = 0,
+    run_at = CURRENT_TIMESTAMP
+WHERE outbox_id = $1 AND status = 'dead';
+
+-- name: RequeueAllDeadOutboxMessages :execrows
+UPDATE outbox
+SET
+    status = 'pending',
+    attempts = 0,
+    run_at = CURRENT_TIMESTAMP
+WHERE status = 'dead';
+
+-- name: SkipDeadOutboxMessage :execrows
+UPDATE outbox
+SET status = 'skipped'
+WHERE outbox_id = $1 AND status = 'dead';
+
+-- name: SkipAllDeadOutboxMessages :execrows
+UPDATE outbox
+SET status = 'skipped'
+WHERE status = 'dead';
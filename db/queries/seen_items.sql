@@ -0,0 +1,47 @@
+-- name: DeleteSeenItems :execrows
+DELETE FROM seen_items
+USING sources
+WHERE
+    seen_items.source_id = sources.source_id
+    AND sources.name = $1
+    AND seen_items.status = 'reported'
+    AND seen_items.created_at < $2;
+
+-- name: GetSeenItem :one
+SELECT
+    seen_items.item_key,
+    seen_items.item_version,
+    seen_items.status,
+    seen_items.attempts,
+    seen_items.last_error,
+    seen_items.source_item_id,
+    seen_items.telegram_message_id,
+    seen_items.item
+FROM seen_items
+INNER JOIN sources ON seen_items.source_id = sources.source_id
+WHERE sources.name = $1 AND seen_items.item_key = $2;
+
+-- name: UpsertSeenItem :exec
+INSERT INTO seen_items (
+    source_id,
+    item_key,
+    item_version,
+    status,
+    attempts,
+    last_error,
+    source_item_id,
+    telegram_message_id,
+    item,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP
+) ON CONFLICT (source_id, item_key) DO UPDATE
+SET
+    item_version = excluded.item_version,
+    status = excluded.status,
+    attempts = excluded.attempts,
+    last_error = excluded.last_error,
+    source_item_id = excluded.source_item_id,
+    telegram_message_id = excluded.telegram_message_id,
+    item = excluded.item;
+
@@ -13,3 +13,59 @@ WHERE name = $1;
 
 -- name: GetSourceIdByName :one
 SELECT source_id FROM sources WHERE name = $1;
+
+-- name: CreateFeedSource :one
+INSERT INTO sources (
+    name, feed_url, update_interval, category, created_at, updated_at
+) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING source_id;
+
+-- name: ListFeedSources :many
+SELECT name, feed_url, update_interval, category
+FROM sources
+WHERE feed_url IS NOT NULL
+ORDER BY name;
+
+-- name: GetSourceHTTPCacheByName :one
+SELECT http_etag, http_last_modified, fetch_not_before
+FROM sources
+WHERE name = $1;
+
+-- name: SetSourceHTTPCacheByName :exec
+UPDATE sources
+SET
+    http_etag = $2,
+    http_last_modified = $3,
+    fetch_not_before = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE name = $1;
+
+-- name: GetSourcePollIntervalByName :one
+SELECT poll_interval FROM sources WHERE name = $1;
+
+-- name: SetSourcePollIntervalByName :exec
+UPDATE sources
+SET poll_interval = $2, updated_at = CURRENT_TIMESTAMP
+WHERE name = $1;
+
+-- name: GetSourceNextRunAtByName :one
+SELECT next_run_at FROM sources WHERE name = $1;
+
+-- name: SetSourceNextRunAtByName :exec
+UPDATE sources
+SET next_run_at = $2, updated_at = CURRENT_TIMESTAMP
+WHERE name = $1;
+
+-- name: ListSourcesNextRunAt :many
+SELECT name, next_run_at, last_fetched_at
+FROM sources
+WHERE next_run_at IS NOT NULL
+ORDER BY next_run_at;
+
+-- name: GetSourceDiscoveredFeedByName :one
+SELECT site_url, discovered_feed_url FROM sources WHERE name = $1;
+
+-- name: SetSourceDiscoveredFeedByName :exec
+UPDATE sources
+SET site_url = $2, discovered_feed_url = $3, updated_at = CURRENT_TIMESTAMP
+WHERE name = $1;
@@ -2,12 +2,96 @@
 INSERT INTO sources_items (
     source_id,
     url,
+    canonical_url,
     title,
     text_content,
     excerpt,
     language,
     published_at,
+    fingerprint,
+    updated,
     created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP
-) RETURNING source_item_id;
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP
+)
+ON CONFLICT (canonical_url) WHERE canonical_url IS NOT NULL AND NOT updated DO NOTHING
+RETURNING source_item_id;
+
+-- name: SetSourceItemTelegramMessageID :exec
+UPDATE sources_items
+SET telegram_message_id = $2
+WHERE source_item_id = $1;
+
+-- name: GetSourceItemIDByCanonicalURL :one
+SELECT source_item_id
+FROM sources_items
+WHERE canonical_url = $1 AND NOT updated;
+
+-- name: CreateSourceItemLink :exec
+INSERT INTO sources_items_links (source_item_id, source_id, created_at)
+VALUES ($1, $2, CURRENT_TIMESTAMP)
+ON CONFLICT (source_item_id, source_id) DO NOTHING;
+
+-- name: ListRecentSourceItemFingerprints :many
+SELECT source_item_id, fingerprint
+FROM sources_items
+WHERE
+    fingerprint IS NOT NULL
+    AND created_at > $1
+    AND source_id IS DISTINCT FROM (
+        SELECT sources.source_id FROM sources WHERE sources.name = $2
+    );
+
+-- name: ListNearSourceItemFingerprints :many
+SELECT source_item_id, fingerprint
+FROM sources_items
+WHERE
+    fingerprint IS NOT NULL
+    AND created_at > $1
+    AND source_id IS DISTINCT FROM (
+        SELECT sources.source_id FROM sources WHERE sources.name = $2
+    )
+    AND (
+        fingerprint_band_0 = $3
+        OR fingerprint_band_1 = $4
+        OR fingerprint_band_2 = $5
+        OR fingerprint_band_3 = $6
+    );
+
+-- name: GetSourceItemCoverage :one
+SELECT
+    sources_items.url,
+    sources_items.telegram_message_id,
+    count(DISTINCT sources_items_links.source_id) AS other_sources
+FROM sources_items
+LEFT JOIN sources_items_links
+    ON
+        sources_items.source_item_id = sources_items_links.source_item_id
+        AND sources_items.source_id != sources_items_links.source_id
+WHERE sources_items.source_item_id = $1
+GROUP BY sources_items.source_item_id;
+
+-- name: GetSourceItemsActivity :one
+SELECT
+    count(*) AS items,
+    min(sources_items.published_at)::TIMESTAMPTZ AS first_published_at,
+    max(sources_items.published_at)::TIMESTAMPTZ AS last_published_at
+FROM sources_items
+INNER JOIN sources ON sources_items.source_id = sources.source_id
+WHERE sources.name = $1 AND sources_items.published_at > $2;
+
+-- name: GetTelegramMessage :one
+SELECT
+    max(sources_items.source_item_id)::INT AS source_item_id,
+    (array_agg(sources_items.url ORDER BY sources_items.source_item_id DESC))[1]::TEXT AS url,
+    count(DISTINCT sources_items.source_item_id) > 1 AS updated,
+    count(DISTINCT sources_items_links.source_id) AS other_sources,
+    count(reactions.source_item_id) > 0 AS reacted
+FROM sources_items
+LEFT JOIN sources_items_links
+    ON
+        sources_items.source_item_id = sources_items_links.source_item_id
+        AND sources_items.source_id != sources_items_links.source_id
+LEFT JOIN reactions ON sources_items.source_item_id = reactions.source_item_id
+WHERE sources_items.telegram_message_id = $1
+HAVING count(sources_items.source_item_id) > 0;
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/storage"
+)
+
+// outboxIDPrefix distinguishes IDs of dead outbox messages from IDs of dead jobs.
+const outboxIDPrefix = "o"
+
+// runDeadList implements "dead-list" subcommand which prints jobs and outbox messages failed after all attempts.
+func runDeadList(ctx context.Context, s *storage.PostgreSQL, args []string) error {
+	fset := flag.NewFlagSet("dead-list", flag.ExitOnError)
+	limit := fset.Int("limit", 100, "maximum number of jobs and of outbox messages to print")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: insightcourier dead-list [flags]")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	jobs, err := s.ListDeadJobs(ctx, *limit)
+	if err != nil {
+		return err
+	}
+	messages, err := s.ListDeadOutboxMessages(ctx, *limit)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tSOURCE\tLINK\tFAILED AT\tATTEMPTS\tERROR")
+	for _, j := range jobs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%s\n", j.ID, j.Type, j.SourceName, j.Link, j.FailedAt.Format(time.DateTime), j.Attempts, j.LastError)
+	}
+	for _, m := range messages {
+		fmt.Fprintf(w, "%s%d\t%s_message\t%s\t%s\t%s\t%d\t%s\n", outboxIDPrefix, m.ID, m.Kind, m.SourceName, m.Link, m.FailedAt.Format(time.DateTime), m.Attempts, m.LastError)
+	}
+	return w.Flush()
+}
+
+// deadActions apply the same action to dead jobs and to dead outbox messages.
+type deadActions struct {
+	job         func(context.Context, int64) error
+	allJobs     func(context.Context) (int, error)
+	message     func(context.Context, int64) error
+	allMessages func(context.Context) (int, error)
+}
+
+// runDeadRetry implements "dead-retry" subcommand which puts dead jobs and outbox messages back to the queue.
+func runDeadRetry(ctx context.Context, s *storage.PostgreSQL, args []string) error {
+	return runDeadAction(ctx, "dead-retry", args, deadActions{
+		job:         s.RequeueDeadJob,
+		allJobs:     s.RequeueAllDeadJobs,
+		message:     s.RequeueDeadOutboxMessage,
+		allMessages: s.RequeueAllDeadOutboxMessages,
+	})
+}
+
+// runDeadSkip implements "dead-skip" subcommand which marks dead jobs and outbox messages as skipped.
+func runDeadSkip(ctx context.Context, s *storage.PostgreSQL, args []string) error {
+	return runDeadAction(ctx, "dead-skip", args, deadActions{
+		job:         s.SkipDeadJob,
+		allJobs:     s.SkipAllDeadJobs,
+		message:     s.SkipDeadOutboxMessage,
+		allMessages: s.SkipAllDeadOutboxMessages,
+	})
+}
+
+func runDeadAction(ctx context.Context, name string, args []string, actions deadActions) error {
+	fset := flag.NewFlagSet(name, flag.ExitOnError)
+	allJobs := fset.Bool("all", false, "apply to all dead jobs and outbox messages")
+	fset.Usage = func() {
+		fmt.Fprintf(fset.Output(), "Usage: insightcourier %s [-all] [job-id|o<message-id>...]\n", name)
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	if *allJobs {
+		jobs, err := actions.allJobs(ctx)
+		if err != nil {
+			return err
+		}
+		messages, err := actions.allMessages(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d jobs and %d outbox messages processed\n", jobs, messages)
+		return nil
+	}
+
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return errors.New("job IDs, outbox message IDs or -all flag should be provided")
+	}
+
+	for _, arg := range fset.Args() {
+		if v, ok := strings.CutPrefix(arg, outboxIDPrefix); ok {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid outbox message ID %s. %w", arg, err)
+			}
+			if err := actions.message(ctx, id); err != nil {
+				if errors.Is(err, storage.ErrOutboxMessageNotFound) {
+					return fmt.Errorf("dead outbox message %d not found", id)
+				}
+				return err
+			}
+			continue
+		}
+
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid job ID %s. %w", arg, err)
+		}
+		if err := actions.job(ctx, id); err != nil {
+			if errors.Is(err, storage.ErrJobNotFound) {
+				return fmt.Errorf("dead job %d not found", id)
+			}
+			return err
+		}
+	}
+	return nil
+}
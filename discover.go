@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/feed"
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+	"github.com/pavelpuchok/insightcourier/storage"
+)
+
+const (
+	discoverAttempts   = 3
+	discoverRetryDelay = 10 * time.Second
+)
+
+// runDiscover implements "discover" subcommand which prints feeds found on the website.
+func runDiscover(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("discover", flag.ExitOnError)
+	fsURL := fset.String("flaresolverr", os.Getenv("IC_FLARESOLVERR_URL"), "FlareSolverr URL used when the site can't be fetched directly")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: insightcourier discover [flags] <site-url>")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	if fset.NArg() != 1 {
+		fset.Usage()
+		return errors.New("site URL should be provided")
+	}
+
+	var fs feed.Solver
+	if *fsURL != "" {
+		fs = &flaresolverr.FlareSolverr{URL: *fsURL}
+	}
+
+	candidates, err := feed.NewDiscoverer(fs).Discover(ctx, fset.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "URL\tTYPE\tITEMS\tADVERTISED\tTITLE")
+	for _, c := range candidates {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%t\t%s\n", c.URL, c.Type, c.Items, c.Advertised, c.Title)
+	}
+	return w.Flush()
+}
+
+// discoverFeedURL returns the feed of the source site. The discovered feed is stored, so the site
+// is discovered again only when the source siteUrl changes. Discovery is retried with backoff.
+func discoverFeedURL(ctx context.Context, s *storage.PostgreSQL, d *feed.Discoverer, source, siteURL string) (string, error) {
+	if _, err := s.CreateSource(ctx, source); err != nil && !errors.Is(err, storage.ErrSourceAlreadyExists) {
+		return "", err
+	}
+
+	stored, err := s.GetDiscoveredFeed(ctx, source)
+	if err != nil {
+		return "", err
+	}
+	if stored.SiteURL == siteURL && stored.FeedURL != "" {
+		return stored.FeedURL, nil
+	}
+
+	delay := discoverRetryDelay
+	for attempt := 1; ; attempt++ {
+		candidates, err := d.Discover(ctx, siteURL)
+		if err == nil {
+			feedURL := candidates[0].URL
+			if err := s.SetDiscoveredFeed(ctx, source, storage.DiscoveredFeed{SiteURL: siteURL, FeedURL: feedURL}); err != nil {
+				return "", err
+			}
+			slog.Info("Source feed discovered", slog.String("source.name", source), slog.String("source.feedUrl", feedURL))
+			return feedURL, nil
+		}
+		if attempt == discoverAttempts {
+			return "", fmt.Errorf("failed to discover feed of source %s (%s). %w", source, siteURL, err)
+		}
+
+		slog.Warn("Failed to discover source feed, retrying", slog.String("source.name", source), slog.Int("attempt", attempt), slog.String("error", err.Error()))
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
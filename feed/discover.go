@@ -0,0 +1,204 @@
+package feed
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+)
+
+// commonFeedPaths are probed when the page doesn't advertise feeds explicitly.
+var commonFeedPaths = []string{"/feed", "/rss", "/feed.xml", "/rss.xml", "/atom.xml", "/index.xml", "/feed.json"}
+
+var feedLinkTypes = map[string]struct{}{
+	"application/rss+xml":   {},
+	"application/atom+xml":  {},
+	"application/feed+json": {},
+	"application/json":      {},
+	"text/xml":              {},
+	"application/xml":       {},
+}
+
+type FeedCandidate struct {
+	URL   string
+	Title string
+	Type  string
+	Items int
+	// Advertised is true when the feed is referenced by <link rel="alternate"> on the page.
+	Advertised bool
+}
+
+// Discoverer finds feeds published by a website.
+type Discoverer struct {
+	client *http.Client
+	parser *gofeed.Parser
+	fs     Solver
+}
+
+// NewDiscoverer creates Discoverer. If fs is not nil, it is used to get the page
+// when direct request fails.
+func NewDiscoverer(fs Solver) *Discoverer {
+	return &Discoverer{
+		client: http.DefaultClient,
+		parser: gofeed.NewParser(),
+		fs:     fs,
+	}
+}
+
+// Discover returns valid feeds of the site ordered from the best candidate.
+func (d *Discoverer) Discover(ctx context.Context, siteURL string) ([]FeedCandidate, error) {
+	base, err := url.Parse(siteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid site URL %s. %w", siteURL, err)
+	}
+
+	// the URL may already point to a feed
+	if c, err := d.validate(ctx, siteURL); err == nil {
+		return []FeedCandidate{c}, nil
+	}
+
+	page, err := d.getPage(ctx, siteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page %s. %w", siteURL, err)
+	}
+
+	advertised := make(map[string]struct{})
+	var urls []string
+	doc.Find(`link[rel~="alternate"][href]`).Each(func(_ int, s *goquery.Selection) {
+		t := strings.ToLower(strings.TrimSpace(s.AttrOr("type", "")))
+		if _, has := feedLinkTypes[t]; !has {
+			return
+		}
+
+		u, err := base.Parse(strings.TrimSpace(s.AttrOr("href", "")))
+		if err != nil {
+			return
+		}
+
+		if _, has := advertised[u.String()]; has {
+			return
+		}
+		advertised[u.String()] = struct{}{}
+		urls = append(urls, u.String())
+	})
+
+	for _, p := range commonFeedPaths {
+		u := base.ResolveReference(&url.URL{Path: p}).String()
+		if _, has := advertised[u]; !has {
+			urls = append(urls, u)
+		}
+	}
+
+	var result []FeedCandidate
+	for _, u := range urls {
+		c, err := d.validate(ctx, u)
+		if err != nil {
+			continue
+		}
+		_, c.Advertised = advertised[u]
+		result = append(result, c)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no feeds found at %s", siteURL)
+	}
+
+	slices.SortStableFunc(result, func(a, b FeedCandidate) int {
+		if a.Advertised != b.Advertised {
+			if a.Advertised {
+				return -1
+			}
+			return 1
+		}
+		if r := cmp.Compare(feedTypeRank(a.Type), feedTypeRank(b.Type)); r != 0 {
+			return r
+		}
+		return cmp.Compare(b.Items, a.Items)
+	})
+
+	return result, nil
+}
+
+func feedTypeRank(t string) int {
+	switch t {
+	case "atom":
+		return 0
+	case "rss":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (d *Discoverer) validate(ctx context.Context, u string) (FeedCandidate, error) {
+	f, err := d.parser.ParseURLWithContext(u, ctx)
+	if err != nil {
+		return FeedCandidate{}, err
+	}
+
+	return FeedCandidate{
+		URL:   u,
+		Title: f.Title,
+		Type:  f.FeedType,
+		Items: len(f.Items),
+	}, nil
+}
+
+func (d *Discoverer) getPage(ctx context.Context, siteURL string) (string, error) {
+	page, err := d.getPageDirect(ctx, siteURL)
+	if err == nil {
+		return page, nil
+	}
+
+	if d.fs == nil {
+		return "", err
+	}
+
+	fsResp, fsErr := d.fs.Get(ctx, siteURL, flaresolverr.WithDisabledMedia())
+	if fsErr != nil {
+		return "", fmt.Errorf("failed to get page %s. %w", siteURL, errors.Join(err, fsErr))
+	}
+	if fsResp.Status != "ok" {
+		return "", fmt.Errorf("unexpected FlareSolverr status: status=%s message=%s", fsResp.Status, fsResp.Message)
+	}
+
+	return fsResp.Solution.Response, nil
+}
+
+func (d *Discoverer) getPageDirect(ctx context.Context, siteURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, siteURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to build request. %w", err)
+	}
+
+	r, err := d.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to get page %s. %w", siteURL, err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status for %s: %s", siteURL, r.Status)
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read page %s. %w", siteURL, err)
+	}
+
+	return string(b), nil
+}
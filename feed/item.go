@@ -2,10 +2,25 @@ package feed
 
 import "time"
 
+type ItemKind int8
+
+const (
+	ItemKindArticle ItemKind = iota
+	ItemKindPost
+	ItemKindComment
+)
+
 type Item struct {
+	ID          string
+	Kind        ItemKind
 	Source      string
 	Title       string
 	Description string
 	Link        string
+	Permalink   string
+	Score       int
+	Comments    int
 	Time        time.Time
+	// Version changes when the item is updated by the publisher. Empty if source doesn't track updates.
+	Version string
 }
@@ -0,0 +1,237 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Searcher runs search query. Results are not expected to be ordered by time.
+type Searcher interface {
+	Search(ctx context.Context, query string) ([]Item, error)
+}
+
+// Query is a source which runs the same search query on every fetch. Since search results
+// are not time-ordered, the since watermark is ignored and all results are returned,
+// so they have to be deduplicated by item ID by the caller. Results older than maxAge are dropped,
+// so the caller may forget items after maxAge.
+type Query struct {
+	searcher Searcher
+	query    string
+	maxAge   time.Duration
+}
+
+func NewQuery(searcher Searcher, query string, maxAge time.Duration) *Query {
+	return &Query{
+		searcher: searcher,
+		query:    query,
+		maxAge:   maxAge,
+	}
+}
+
+func (q *Query) Fetch(ctx context.Context, _ time.Time) ([]Item, error) {
+	items, err := q.searcher.Search(ctx, q.query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %q. %w", q.query, err)
+	}
+
+	if q.maxAge == 0 {
+		return items, nil
+	}
+	oldest := time.Now().Add(-q.maxAge)
+	return slices.DeleteFunc(items, func(it Item) bool {
+		return !it.Time.IsZero() && it.Time.Before(oldest)
+	}), nil
+}
+
+// NitterSearch searches tweets (e.g. hashtags) through Nitter-compatible instances.
+type NitterSearch struct {
+	nitter *nitterClient
+
+	mu sync.Mutex
+	// last are results of the previous search by query and tweet ID. They are reused
+	// to not expand links of the same tweets on every search.
+	last map[string]map[string]Item
+}
+
+func NewNitterSearch(instances []string) *NitterSearch {
+	return &NitterSearch{
+		nitter: newNitterClient(instances),
+	}
+}
+
+func (s *NitterSearch) Search(ctx context.Context, query string) ([]Item, error) {
+	q := url.Values{}
+	q.Set("f", "tweets")
+	q.Set("q", query)
+
+	f, instance, err := s.nitter.feed(ctx, "/search/rss?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tweets. %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := s.last[query]
+	found := make(map[string]Item, len(f.Items))
+	result := make([]Item, 0, len(f.Items))
+	for _, it := range f.Items {
+		t, err := parseTweet(instance, it)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tweet. %w", err)
+		}
+
+		if _, ok := found[t.id]; ok {
+			continue
+		}
+		item, ok := last[t.id]
+		if !ok {
+			item = s.nitter.tweetItem(ctx, query, t)
+		}
+		found[t.id] = item
+		result = append(result, item)
+	}
+
+	if s.last == nil {
+		s.last = make(map[string]map[string]Item)
+	}
+	s.last[query] = found
+
+	return result, nil
+}
+
+// RedditSearch searches Reddit posts, optionally restricted to a single subreddit.
+type RedditSearch struct {
+	redditClient
+	subreddit string
+	limit     int
+}
+
+func NewRedditSearch(subreddit string, limit int, opts ...RedditOption) *RedditSearch {
+	return &RedditSearch{
+		redditClient: newRedditClient(opts...),
+		subreddit:    subreddit,
+		limit:        limit,
+	}
+}
+
+func (s *RedditSearch) Search(ctx context.Context, query string) ([]Item, error) {
+	q := url.Values{}
+	q.Set("q", query)
+	q.Set("sort", "new")
+	if s.limit > 0 {
+		q.Set("limit", strconv.Itoa(s.limit))
+	}
+
+	p := "/search"
+	if s.subreddit != "" {
+		p = "/r/" + s.subreddit + "/search"
+		q.Set("restrict_sr", "1")
+	}
+
+	l, err := s.listing(ctx, p, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search Reddit. %w", err)
+	}
+
+	result := make([]Item, 0, len(l.Data.Children))
+	for _, t := range l.Data.Children {
+		if t.Kind != "t3" {
+			continue
+		}
+		result = append(result, s.postItem(t))
+	}
+
+	return result, nil
+}
+
+const DefaultHackerNewsBaseURL = "https://hn.algolia.com"
+
+// HackerNewsSearch searches Hacker News stories through the Algolia search API.
+type HackerNewsSearch struct {
+	baseURL string
+	limit   int
+	client  *http.Client
+}
+
+func NewHackerNewsSearch(limit int) *HackerNewsSearch {
+	return &HackerNewsSearch{
+		baseURL: DefaultHackerNewsBaseURL,
+		limit:   limit,
+		client:  http.DefaultClient,
+	}
+}
+
+type hackerNewsResponse struct {
+	Hits []struct {
+		ObjectID    string `json:"objectID"`
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		StoryText   string `json:"story_text"`
+		Points      int    `json:"points"`
+		NumComments int    `json:"num_comments"`
+		CreatedAtI  int64  `json:"created_at_i"`
+	} `json:"hits"`
+}
+
+func (s *HackerNewsSearch) Search(ctx context.Context, query string) ([]Item, error) {
+	q := url.Values{}
+	q.Set("query", query)
+	q.Set("tags", "story")
+	if s.limit > 0 {
+		q.Set("hitsPerPage", strconv.Itoa(s.limit))
+	}
+	u := s.baseURL + "/api/v1/search_by_date?" + q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build Hacker News request. %w", err)
+	}
+
+	r, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to make Hacker News request to %s. %w", u, err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected Hacker News response status for %s: %s", u, r.Status)
+	}
+
+	var res hackerNewsResponse
+	err = json.NewDecoder(r.Body).Decode(&res)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode Hacker News response. %w", err)
+	}
+
+	result := make([]Item, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		permalink := "https://news.ycombinator.com/item?id=" + h.ObjectID
+		link := h.URL
+		if link == "" {
+			link = permalink
+		}
+
+		result = append(result, Item{
+			ID:          h.ObjectID,
+			Kind:        ItemKindPost,
+			Source:      "news.ycombinator.com",
+			Title:       h.Title,
+			Description: h.StoryText,
+			Link:        link,
+			Permalink:   permalink,
+			Score:       h.Points,
+			Comments:    h.NumComments,
+			Time:        time.Unix(h.CreatedAtI, 0),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,201 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultRedditBaseURL   = "https://www.reddit.com"
+	DefaultRedditUserAgent = DefaultUserAgent
+)
+
+type redditClient struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+type RedditOption = func(*redditClient)
+
+func WithRedditBaseURL(u string) RedditOption {
+	return func(c *redditClient) {
+		c.baseURL = u
+	}
+}
+
+func WithRedditUserAgent(ua string) RedditOption {
+	return func(c *redditClient) {
+		c.userAgent = ua
+	}
+}
+
+func newRedditClient(opts ...RedditOption) redditClient {
+	c := redditClient{
+		baseURL:   DefaultRedditBaseURL,
+		userAgent: DefaultRedditUserAgent,
+		client:    http.DefaultClient,
+	}
+	for _, optFunc := range opts {
+		optFunc(&c)
+	}
+	return c
+}
+
+type redditListing struct {
+	Kind string `json:"kind"`
+	Data struct {
+		After    string        `json:"after"`
+		Children []redditThing `json:"children"`
+	} `json:"data"`
+}
+
+type redditThing struct {
+	Kind string `json:"kind"`
+	Data struct {
+		Name          string  `json:"name"`
+		Title         string  `json:"title"`
+		SelfText      string  `json:"selftext"`
+		Body          string  `json:"body"`
+		URL           string  `json:"url"`
+		Permalink     string  `json:"permalink"`
+		IsSelf        bool    `json:"is_self"`
+		Score         int     `json:"score"`
+		NumComments   int     `json:"num_comments"`
+		CreatedUTC    float64 `json:"created_utc"`
+		Subreddit     string  `json:"subreddit"`
+		Author        string  `json:"author"`
+		LinkTitle     string  `json:"link_title"`
+		LinkPermalink string  `json:"link_permalink"`
+	} `json:"data"`
+}
+
+func (c *redditClient) listing(ctx context.Context, path string, query url.Values) (*redditListing, error) {
+	query.Set("raw_json", "1")
+	u := c.baseURL + path + ".json?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build Reddit request. %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	r, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to make Reddit request to %s. %w", u, err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected Reddit response status for %s: %s", u, r.Status)
+	}
+
+	var l redditListing
+	err = json.NewDecoder(r.Body).Decode(&l)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode Reddit listing from %s. %w", u, err)
+	}
+
+	return &l, nil
+}
+
+func (c *redditClient) permalink(p string) string {
+	if p == "" {
+		return ""
+	}
+	return c.baseURL + p
+}
+
+func (c *redditClient) postItem(t redditThing) Item {
+	permalink := c.permalink(t.Data.Permalink)
+	link := t.Data.URL
+	if t.Data.IsSelf || link == "" {
+		link = permalink
+	}
+
+	return Item{
+		ID:          t.Data.Name,
+		Kind:        ItemKindPost,
+		Source:      "r/" + t.Data.Subreddit,
+		Title:       t.Data.Title,
+		Description: t.Data.SelfText,
+		Link:        link,
+		Permalink:   permalink,
+		Score:       t.Data.Score,
+		Comments:    t.Data.NumComments,
+		Time:        time.Unix(int64(t.Data.CreatedUTC), 0),
+	}
+}
+
+func (c *redditClient) commentItem(t redditThing) Item {
+	permalink := c.permalink(t.Data.Permalink)
+
+	return Item{
+		ID:          t.Data.Name,
+		Kind:        ItemKindComment,
+		Source:      "r/" + t.Data.Subreddit,
+		Title:       t.Data.LinkTitle,
+		Description: t.Data.Body,
+		Link:        permalink,
+		Permalink:   permalink,
+		Score:       t.Data.Score,
+		Time:        time.Unix(int64(t.Data.CreatedUTC), 0),
+	}
+}
+
+// Reddit fetches posts from a subreddit listing.
+type Reddit struct {
+	redditClient
+	subreddit  string
+	sort       string
+	timeWindow string
+	limit      int
+}
+
+// NewReddit creates fetcher for the subreddit listing. sort is one of new, hot or top;
+// timeWindow (hour, day, week, month, year, all) is only used by the top listing.
+func NewReddit(subreddit, sort, timeWindow string, limit int, opts ...RedditOption) *Reddit {
+	return &Reddit{
+		redditClient: newRedditClient(opts...),
+		subreddit:    subreddit,
+		sort:         sort,
+		timeWindow:   timeWindow,
+		limit:        limit,
+	}
+}
+
+func (r *Reddit) Fetch(ctx context.Context, since time.Time) ([]Item, error) {
+	q := url.Values{}
+	if r.limit > 0 {
+		q.Set("limit", strconv.Itoa(r.limit))
+	}
+	if r.sort == "top" && r.timeWindow != "" {
+		q.Set("t", r.timeWindow)
+	}
+
+	l, err := r.listing(ctx, "/r/"+r.subreddit+"/"+r.sort, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subreddit %s. %w", r.subreddit, err)
+	}
+
+	result := make([]Item, 0, len(l.Data.Children))
+	for _, t := range l.Data.Children {
+		if t.Kind != "t3" {
+			continue
+		}
+
+		it := r.postItem(t)
+		if !it.Time.After(since) {
+			continue
+		}
+
+		result = append(result, it)
+	}
+
+	return result, nil
+}
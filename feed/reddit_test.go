@@ -0,0 +1,95 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+// newRedditServer serves the recorded listing and records query of the last request.
+func newRedditServer(t *testing.T, path string) (*httptest.Server, *url.Values) {
+	t.Helper()
+
+	listing, err := os.ReadFile("testdata/reddit_listing.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := &url.Values{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		if ua := r.Header.Get("User-Agent"); ua != "test-agent" {
+			t.Errorf("unexpected User-Agent %q", ua)
+		}
+		*query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(listing)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, query
+}
+
+func TestRedditFetch(t *testing.T) {
+	srv, query := newRedditServer(t, "/r/golang/top.json")
+	r := NewReddit("golang", "top", "day", 25, WithRedditBaseURL(srv.URL), WithRedditUserAgent("test-agent"))
+
+	// the last post of the listing is older
+	since := time.Unix(1723550000, 0)
+	items, err := r.Fetch(context.Background(), since)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := query.Get("limit"); got != "25" {
+		t.Errorf("limit = %q, want 25", got)
+	}
+	if got := query.Get("t"); got != "day" {
+		t.Errorf("t = %q, want day", got)
+	}
+	if got := query.Get("raw_json"); got != "1" {
+		t.Errorf("raw_json = %q, want 1", got)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+
+	link := items[0]
+	if link.ID != "t3_1g4x9a1" || link.Kind != ItemKindPost || link.Source != "r/golang" {
+		t.Errorf("unexpected link post %+v", link)
+	}
+	if link.Link != "https://go.dev/blog/go1.23" {
+		t.Errorf("link post Link = %s, want the linked article", link.Link)
+	}
+	if want := srv.URL + "/r/golang/comments/1g4x9a1/go_123_is_released/"; link.Permalink != want {
+		t.Errorf("link post Permalink = %s, want %s", link.Permalink, want)
+	}
+	if link.Score != 412 || link.Comments != 57 || !link.Time.Equal(time.Unix(1723593600, 0)) {
+		t.Errorf("unexpected link post stats %+v", link)
+	}
+
+	self := items[1]
+	if want := srv.URL + "/r/golang/comments/1g4w7b5/integration_tests_with_postgres/"; self.Link != want || self.Permalink != want {
+		t.Errorf("self post Link = %s, Permalink = %s, want %s", self.Link, self.Permalink, want)
+	}
+	if self.Description != "How do you structure **integration tests** with a real database?" {
+		t.Errorf("self post Description = %q", self.Description)
+	}
+}
+
+func TestRedditFetchStatus(t *testing.T) {
+	srv, _ := newRedditServer(t, "/r/golang/new.json")
+	r := NewReddit("missing", "new", "", 0, WithRedditBaseURL(srv.URL), WithRedditUserAgent("test-agent"))
+
+	if _, err := r.Fetch(context.Background(), time.Time{}); err == nil {
+		t.Fatal("expected error for unknown subreddit")
+	}
+}
@@ -0,0 +1,132 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redditUserMaxPages limits how deep user listings are paged through when since watermark is far in the past.
+const redditUserMaxPages = 10
+
+// RedditUser fetches submissions and, optionally, comments of a Reddit user.
+type RedditUser struct {
+	redditClient
+	username        string
+	includeComments bool
+	subreddits      map[string]struct{}
+	minScore        int
+	scoreWindow     time.Duration
+}
+
+// NewRedditUser creates fetcher for the user activity. Items are dropped if subreddits is not empty
+// and doesn't contain item's subreddit. If minScore is set, items scored less are held back: fresh items
+// rarely have many votes, so items posted within scoreWindow are fetched again regardless of since and
+// returned once they reach minScore. The caller deduplicates items returned on several fetches by ID.
+func NewRedditUser(username string, includeComments bool, subreddits []string, minScore int, scoreWindow time.Duration, opts ...RedditOption) *RedditUser {
+	u := &RedditUser{
+		redditClient:    newRedditClient(opts...),
+		username:        username,
+		includeComments: includeComments,
+		minScore:        minScore,
+		scoreWindow:     scoreWindow,
+	}
+
+	if len(subreddits) > 0 {
+		u.subreddits = make(map[string]struct{}, len(subreddits))
+		for _, s := range subreddits {
+			u.subreddits[strings.ToLower(s)] = struct{}{}
+		}
+	}
+
+	return u
+}
+
+func (u *RedditUser) Fetch(ctx context.Context, since time.Time) ([]Item, error) {
+	if u.minScore != 0 {
+		if from := time.Now().Add(-u.scoreWindow); from.Before(since) {
+			since = from
+		}
+	}
+
+	result, err := u.fetchListing(ctx, "submitted", since)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.includeComments {
+		return result, nil
+	}
+
+	comments, err := u.fetchListing(ctx, "comments", since)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(result, comments...), nil
+}
+
+func (u *RedditUser) fetchListing(ctx context.Context, listing string, since time.Time) ([]Item, error) {
+	var result []Item
+	after := ""
+
+	for range redditUserMaxPages {
+		q := url.Values{}
+		q.Set("sort", "new")
+		q.Set("limit", "100")
+		if after != "" {
+			q.Set("after", after)
+		}
+
+		l, err := u.listing(ctx, "/user/"+u.username+"/"+listing, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s of Reddit user %s. %w", listing, u.username, err)
+		}
+
+		for _, t := range l.Data.Children {
+			var it Item
+			switch t.Kind {
+			case "t3":
+				it = u.postItem(t)
+			case "t1":
+				it = u.commentItem(t)
+			default:
+				continue
+			}
+
+			if !it.Time.After(since) {
+				// listing is sorted by creation time, so everything below is already seen
+				return result, nil
+			}
+
+			if !u.accept(t, it) {
+				continue
+			}
+
+			result = append(result, it)
+		}
+
+		if l.Data.After == "" {
+			break
+		}
+		after = l.Data.After
+	}
+
+	return result, nil
+}
+
+func (u *RedditUser) accept(t redditThing, it Item) bool {
+	if u.minScore != 0 && it.Score < u.minScore {
+		return false
+	}
+
+	if u.subreddits != nil {
+		if _, has := u.subreddits[strings.ToLower(t.Data.Subreddit)]; !has {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,55 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRedditUserFetchScoreWindow(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	score := 3
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user/gopher/submitted.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind":"Listing","data":{"children":[{"kind":"t3","data":{"name":"t3_a","title":"Post","permalink":"/r/golang/comments/a/post/","is_self":true,"score":%d,"created_utc":%d,"subreddit":"golang"}}]}}`,
+			score, created.Unix())
+	}))
+	defer srv.Close()
+
+	u := NewRedditUser("gopher", false, nil, 10, 24*time.Hour, WithRedditBaseURL(srv.URL), WithRedditUserAgent("test-agent"))
+
+	items, err := u.Fetch(context.Background(), created.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("got %d items, want the low scored post held back", len(items))
+	}
+
+	// the post is fetched again even though it's older than since
+	score = 12
+	items, err = u.Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Score != 12 {
+		t.Fatalf("got %+v, want the post once it reached the minimum score", items)
+	}
+
+	// posts older than the window aren't checked anymore
+	u = NewRedditUser("gopher", false, nil, 10, 30*time.Minute, WithRedditBaseURL(srv.URL), WithRedditUserAgent("test-agent"))
+	items, err = u.Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("got %d items, want none outside of the score window", len(items))
+	}
+}
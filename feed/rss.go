@@ -3,50 +3,277 @@ package feed
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
 )
 
+const DefaultUserAgent = "insightcourier/0.1"
+
+// maxFetchDelay caps how long publisher can postpone the next fetch via Cache-Control or Retry-After.
+const maxFetchDelay = 6 * time.Hour
+
+// HTTPCache holds conditional request validators of a feed and the time before which
+// the feed should not be requested again.
+type HTTPCache struct {
+	ETag         string
+	LastModified string
+	NotBefore    time.Time
+}
+
+// SlowDownError is returned when the feed publisher responds with 429 or 503 status.
+// The feed isn't requested before NotBefore.
+type SlowDownError struct {
+	URL       string
+	Status    string
+	NotBefore time.Time
+}
+
+func (e *SlowDownError) Error() string {
+	return fmt.Sprintf("RSS feed publisher of %s asked to slow down: %s, not requested before %s", e.URL, e.Status, e.NotBefore.Format(time.RFC3339))
+}
+
+type HTTPCacheStore interface {
+	GetHTTPCache(ctx context.Context, source string) (HTTPCache, error)
+	SetHTTPCache(ctx context.Context, source string, c HTTPCache) error
+}
+
 type RSS struct {
 	url    string
 	parser *gofeed.Parser
+	client *http.Client
+	source string
+	cache  HTTPCacheStore
+	hint   atomic.Int64
 }
 
-func NewRSS(url string) *RSS {
-	return &RSS{
+type RSSOption = func(*RSS)
+
+// WithHTTPCache enables conditional requests with validators persisted in store under the source name.
+func WithHTTPCache(source string, store HTTPCacheStore) RSSOption {
+	return func(rss *RSS) {
+		rss.source = source
+		rss.cache = store
+	}
+}
+
+func NewRSS(url string, opts ...RSSOption) *RSS {
+	parser := gofeed.NewParser()
+	parser.RSSTranslator = &rssTranslator{}
+
+	rss := &RSS{
 		url:    url,
-		parser: gofeed.NewParser(),
+		parser: parser,
+		client: http.DefaultClient,
 	}
+	for _, optFunc := range opts {
+		optFunc(rss)
+	}
+	return rss
 }
 
-func (rss *RSS) Fetch(ctx context.Context, since time.Time) ([]Item, error) {
-	feed, err := rss.parser.ParseURLWithContext(rss.url, ctx)
+// Fetch returns all items of the feed, since is ignored as publication dates can't be trusted.
+// Items should be deduplicated by GUID or link by the caller.
+func (rss *RSS) Fetch(ctx context.Context, _ time.Time) ([]Item, error) {
+	var cache HTTPCache
+	if rss.cache != nil {
+		var err error
+		cache, err = rss.cache.GetHTTPCache(ctx, rss.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RSS feed HTTP cache. %w", err)
+		}
+
+		if time.Now().Before(cache.NotBefore) {
+			slog.Debug("RSS feed fetch postponed", slog.String("feed.url", rss.url), slog.Time("notBefore", cache.NotBefore))
+			return nil, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rss.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build RSS feed request. %w", err)
+	}
+	req.Header.Set("User-Agent", DefaultUserAgent)
+	if cache.ETag != "" {
+		req.Header.Set("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
 
+	r, err := rss.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch RSS feed from %s. %w", rss.url, err)
 	}
+	defer r.Body.Close()
+
+	now := time.Now()
+	switch r.StatusCode {
+	case http.StatusOK:
+		cache.ETag = r.Header.Get("ETag")
+		cache.LastModified = r.Header.Get("Last-Modified")
+		cache.NotBefore = now.Add(maxAge(r.Header))
+	case http.StatusNotModified:
+		cache.NotBefore = now.Add(maxAge(r.Header))
+		return nil, rss.saveCache(ctx, cache)
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// the feed isn't requested again until Retry-After, the fetch fails to be retried with backoff
+		cache.NotBefore = now.Add(retryAfter(r.Header, now))
+		if err := rss.saveCache(ctx, cache); err != nil {
+			return nil, err
+		}
+		return nil, &SlowDownError{URL: rss.url, Status: r.Status, NotBefore: cache.NotBefore}
+	default:
+		return nil, fmt.Errorf("unexpected RSS feed response status for %s: %s", rss.url, r.Status)
+	}
+
+	feed, err := rss.parser.Parse(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSS feed from %s. %w", rss.url, err)
+	}
+
+	if err := rss.saveCache(ctx, cache); err != nil {
+		return nil, err
+	}
+	rss.hint.Store(int64(updateHint(feed)))
 
 	result := make([]Item, 0, len(feed.Items))
 
 	for _, it := range feed.Items {
 		t := getTime(it)
-		if t.Before(since) || t.Equal(since) {
-			continue
+
+		var version string
+		if it.UpdatedParsed != nil {
+			version = it.UpdatedParsed.UTC().Format(time.RFC3339)
 		}
 
 		result = append(result, Item{
+			ID:          it.GUID,
 			Source:      rss.url,
 			Title:       it.Title,
 			Description: it.Description,
 			Link:        it.Link,
 			Time:        t,
+			Version:     version,
 		})
 	}
 
 	return result, nil
 }
 
+// UpdateHint returns the minimal polling interval requested by the publisher in the last fetched feed,
+// zero if there is no such request.
+func (rss *RSS) UpdateHint() time.Duration {
+	return time.Duration(rss.hint.Load())
+}
+
+func (rss *RSS) saveCache(ctx context.Context, cache HTTPCache) error {
+	if rss.cache == nil {
+		return nil
+	}
+
+	err := rss.cache.SetHTTPCache(ctx, rss.source, cache)
+	if err != nil {
+		return fmt.Errorf("failed to save RSS feed HTTP cache. %w", err)
+	}
+	return nil
+}
+
+// maxAge returns Cache-Control max-age directive value.
+func maxAge(h http.Header) time.Duration {
+	for _, d := range strings.Split(h.Get("Cache-Control"), ",") {
+		v, found := strings.CutPrefix(strings.TrimSpace(d), "max-age=")
+		if !found {
+			continue
+		}
+
+		sec, err := strconv.Atoi(v)
+		if err != nil || sec <= 0 {
+			return 0
+		}
+		return min(time.Duration(sec)*time.Second, maxFetchDelay)
+	}
+	return 0
+}
+
+// retryAfter returns Retry-After header value which is either delay in seconds or HTTP date.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+
+	if sec, err := strconv.Atoi(v); err == nil {
+		return min(time.Duration(max(sec, 0))*time.Second, maxFetchDelay)
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return min(max(t.Sub(now), 0), maxFetchDelay)
+	}
+
+	return 0
+}
+
+// rssTranslator keeps RSS <ttl> which isn't translated to the universal feed by default.
+type rssTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *rssTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	if f, ok := feed.(*rss.Feed); ok && f.TTL != "" {
+		if result.Custom == nil {
+			result.Custom = make(map[string]string)
+		}
+		result.Custom["ttl"] = f.TTL
+	}
+	return result, nil
+}
+
+var syndicationPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// updateHint returns polling interval requested by RSS <ttl> in minutes or by syndication module
+// sy:updatePeriod and sy:updateFrequency. The longest one is used if both are present.
+func updateHint(f *gofeed.Feed) time.Duration {
+	var hint time.Duration
+	if ttl, err := strconv.Atoi(f.Custom["ttl"]); err == nil && ttl > 0 {
+		hint = time.Duration(ttl) * time.Minute
+	}
+
+	sy := f.Extensions["sy"]
+	if len(sy["updatePeriod"]) == 0 {
+		return hint
+	}
+	period, has := syndicationPeriods[strings.TrimSpace(sy["updatePeriod"][0].Value)]
+	if !has {
+		return hint
+	}
+	frequency := 1
+	if len(sy["updateFrequency"]) > 0 {
+		if n, err := strconv.Atoi(strings.TrimSpace(sy["updateFrequency"][0].Value)); err == nil && n > 0 {
+			frequency = n
+		}
+	}
+
+	return max(hint, period/time.Duration(frequency))
+}
+
 func getTime(it *gofeed.Item) time.Time {
 	if it.UpdatedParsed != nil {
 		return *it.UpdatedParsed
@@ -56,5 +283,5 @@ func getTime(it *gofeed.Item) time.Time {
 		return *it.PublishedParsed
 	}
 
-	return time.Now()
+	return time.Time{}
 }
@@ -0,0 +1,97 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryCache keeps HTTP cache of a single feed.
+type memoryCache struct {
+	cache HTTPCache
+}
+
+func (m *memoryCache) GetHTTPCache(_ context.Context, _ string) (HTTPCache, error) {
+	return m.cache, nil
+}
+
+func (m *memoryCache) SetHTTPCache(_ context.Context, _ string, c HTTPCache) error {
+	m.cache = c
+	return nil
+}
+
+func TestRSSFetchSlowDown(t *testing.T) {
+	retryAt := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       time.Time
+	}{
+		{name: "delay in seconds", status: http.StatusTooManyRequests, retryAfter: "120", want: time.Now().Add(2 * time.Minute)},
+		{name: "HTTP date", status: http.StatusServiceUnavailable, retryAfter: retryAt.Format(http.TimeFormat), want: retryAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.Header().Set("Retry-After", tt.retryAfter)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			store := &memoryCache{}
+			rss := NewRSS(srv.URL, WithHTTPCache("test", store))
+
+			_, err := rss.Fetch(context.Background(), time.Time{})
+			var slowDown *SlowDownError
+			if !errors.As(err, &slowDown) {
+				t.Fatalf("expected SlowDownError, got %v", err)
+			}
+			if d := store.cache.NotBefore.Sub(tt.want); d < -5*time.Second || d > 5*time.Second {
+				t.Errorf("NotBefore = %s, want %s", store.cache.NotBefore, tt.want)
+			}
+
+			// the next fetch is skipped until NotBefore
+			items, err := rss.Fetch(context.Background(), time.Time{})
+			if err != nil || items != nil {
+				t.Errorf("next fetch = %v, %v, want skipped", items, err)
+			}
+			if n := requests.Load(); n != 1 {
+				t.Errorf("feed requested %d times, want once", n)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "30", want: 30 * time.Second},
+		{value: "-5", want: 0},
+		{value: strconv.Itoa(int(24 * time.Hour / time.Second)), want: maxFetchDelay},
+		{value: now.Add(time.Hour).UTC().Format(http.TimeFormat), want: time.Hour},
+		{value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.value != "" {
+			h.Set("Retry-After", tt.value)
+		}
+		if got := retryAfter(h, now); (got - tt.want).Abs() > 2*time.Second {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
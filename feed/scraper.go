@@ -0,0 +1,141 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+)
+
+// ScraperSelectors describes where to find items on a list page. Title, Link and Date
+// selectors are applied within the Item element. Empty Link selector means the Item element
+// itself (or its first anchor) holds the link; empty Title selector means the link text is the title.
+type ScraperSelectors struct {
+	Item       string
+	Title      string
+	Link       string
+	Date       string
+	DateLayout string
+}
+
+// Solver is FlareSolverr client, e.g. flaresolverr.FlareSolverr or flaresolverr.Breaker.
+type Solver interface {
+	Get(ctx context.Context, pageURL string, opts ...flaresolverr.Option) (*flaresolverr.GetResponse, error)
+}
+
+// Scraper extracts items from a site list page fetched through FlareSolverr.
+type Scraper struct {
+	url       string
+	selectors ScraperSelectors
+	fs        Solver
+}
+
+func NewScraper(pageURL string, selectors ScraperSelectors, fs Solver) *Scraper {
+	if selectors.DateLayout == "" {
+		selectors.DateLayout = time.RFC3339
+	}
+
+	return &Scraper{
+		url:       pageURL,
+		selectors: selectors,
+		fs:        fs,
+	}
+}
+
+func (s *Scraper) Fetch(ctx context.Context, since time.Time) ([]Item, error) {
+	base, err := url.Parse(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scraper page URL %s. %w", s.url, err)
+	}
+
+	fsResp, err := s.fs.Get(ctx, s.url, flaresolverr.WithDisabledMedia())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page %s. %w", s.url, err)
+	}
+
+	if fsResp.Status != "ok" {
+		return nil, fmt.Errorf("unexpected FlareSolverr status: status=%s message=%s", fsResp.Status, fsResp.Message)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fsResp.Solution.Response))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page %s. %w", s.url, err)
+	}
+
+	var result []Item
+	var errs []error
+	doc.Find(s.selectors.Item).Each(func(_ int, sel *goquery.Selection) {
+		it, err := s.item(base, sel)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+
+		// undated items are passed through and rely on deduplication by link
+		if !it.Time.IsZero() && !it.Time.After(since) {
+			return
+		}
+
+		result = append(result, it)
+	})
+
+	if len(result) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("failed to scrape page %s. %w", s.url, errs[0])
+	}
+
+	return result, nil
+}
+
+func (s *Scraper) item(base *url.URL, sel *goquery.Selection) (Item, error) {
+	linkSel := sel
+	if s.selectors.Link != "" {
+		linkSel = sel.Find(s.selectors.Link).First()
+	}
+	if _, has := linkSel.Attr("href"); !has {
+		linkSel = linkSel.Find("a[href]").First()
+	}
+
+	href, has := linkSel.Attr("href")
+	if !has {
+		return Item{}, fmt.Errorf("link not found in item")
+	}
+
+	link, err := base.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return Item{}, fmt.Errorf("invalid item link %s. %w", href, err)
+	}
+
+	title := strings.TrimSpace(linkSel.Text())
+	if s.selectors.Title != "" {
+		title = strings.TrimSpace(sel.Find(s.selectors.Title).First().Text())
+	}
+
+	var t time.Time
+	if s.selectors.Date != "" {
+		dateSel := sel.Find(s.selectors.Date).First()
+		raw, has := dateSel.Attr("datetime")
+		if !has {
+			raw = dateSel.Text()
+		}
+		raw = strings.TrimSpace(raw)
+
+		if raw != "" {
+			t, err = time.Parse(s.selectors.DateLayout, raw)
+			if err != nil {
+				return Item{}, fmt.Errorf("invalid item date %q. %w", raw, err)
+			}
+		}
+	}
+
+	return Item{
+		ID:     link.String(),
+		Source: s.url,
+		Title:  title,
+		Link:   link.String(),
+		Time:   t,
+	}, nil
+}
@@ -0,0 +1,246 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+)
+
+const twitterBaseURL = "https://x.com"
+
+// nitterClient reads RSS feeds of Nitter-compatible instances. Instances are tried in order
+// starting from the last one which responded successfully.
+type nitterClient struct {
+	instances []string
+	current   atomic.Int32
+	parser    *gofeed.Parser
+	client    *http.Client
+}
+
+func newNitterClient(instances []string) *nitterClient {
+	return &nitterClient{
+		instances: instances,
+		parser:    gofeed.NewParser(),
+		client:    http.DefaultClient,
+	}
+}
+
+func (n *nitterClient) feed(ctx context.Context, p string) (*gofeed.Feed, *url.URL, error) {
+	if len(n.instances) == 0 {
+		return nil, nil, errors.New("no Nitter instances configured")
+	}
+
+	var errs []error
+	start := int(n.current.Load())
+	for i := range n.instances {
+		idx := (start + i) % len(n.instances)
+		instance := n.instances[idx]
+
+		base, err := url.Parse(instance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid Nitter instance URL %s. %w", instance, err))
+			continue
+		}
+
+		f, err := n.parser.ParseURLWithContext(strings.TrimRight(instance, "/")+p, ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Nitter instance %s failed. %w", instance, err))
+			continue
+		}
+
+		n.current.Store(int32(idx))
+		return f, base, nil
+	}
+
+	return nil, nil, fmt.Errorf("all Nitter instances failed. %w", errors.Join(errs...))
+}
+
+// tweet is a Nitter RSS item with retweet and reply markers unwrapped.
+type tweet struct {
+	id        string
+	text      string
+	permalink string
+	isReply   bool
+	links     []string
+	quoteURL  string
+	published time.Time
+}
+
+func parseTweet(instance *url.URL, it *gofeed.Item) (tweet, error) {
+	t := tweet{text: it.Title}
+
+	// Nitter prefixes titles of retweets and replies, but the link and the content
+	// already belong to the original tweet.
+	if strings.HasPrefix(t.text, "RT by @") {
+		if _, text, found := strings.Cut(t.text, ": "); found {
+			t.text = text
+		}
+	}
+	if strings.HasPrefix(t.text, "R to @") {
+		t.isReply = true
+		if _, text, found := strings.Cut(t.text, ": "); found {
+			t.text = text
+		}
+	}
+
+	l, err := url.Parse(it.Link)
+	if err != nil {
+		return t, fmt.Errorf("invalid tweet link %s. %w", it.Link, err)
+	}
+	t.id = path.Base(l.Path)
+	t.permalink = twitterURL(l)
+
+	// undated tweets are left with zero time, fetchers deduplicate them by ID
+	if it.PublishedParsed != nil {
+		t.published = *it.PublishedParsed
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(it.Description))
+	if err != nil {
+		return t, fmt.Errorf("unable to parse tweet content. %w", err)
+	}
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		u, err := instance.Parse(href)
+		if err != nil {
+			return
+		}
+
+		switch {
+		case u.Host == instance.Host || isTwitterHost(u.Host):
+			if strings.Contains(u.Path, "/status/") && t.quoteURL == "" {
+				t.quoteURL = twitterURL(u)
+			}
+		default:
+			t.links = append(t.links, u.String())
+		}
+	})
+
+	return t, nil
+}
+
+func isTwitterHost(h string) bool {
+	switch strings.TrimPrefix(h, "www.") {
+	case "twitter.com", "x.com", "mobile.twitter.com":
+		return true
+	}
+	return false
+}
+
+func twitterURL(u *url.URL) string {
+	return twitterBaseURL + u.Path
+}
+
+// expandLink resolves shortened link (t.co and alike) by following redirects.
+func (n *nitterClient) expandLink(ctx context.Context, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host != "t.co" {
+		return link
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil)
+	if err != nil {
+		return link
+	}
+
+	r, err := n.client.Do(req)
+	if err != nil {
+		slog.Warn("Failed to expand short link", slog.String("link", link), slog.String("error", err.Error()))
+		return link
+	}
+	r.Body.Close()
+
+	return r.Request.URL.String()
+}
+
+func (n *nitterClient) tweetItem(ctx context.Context, source string, t tweet) Item {
+	link := t.permalink
+	switch {
+	case len(t.links) > 0:
+		link = n.expandLink(ctx, t.links[0])
+	case t.quoteURL != "":
+		link = t.quoteURL
+	}
+
+	return Item{
+		ID:          t.id,
+		Kind:        ItemKindPost,
+		Source:      source,
+		Title:       t.text,
+		Description: t.text,
+		Link:        link,
+		Permalink:   t.permalink,
+		Time:        t.published,
+	}
+}
+
+// Twitter fetches user timeline through Nitter-compatible instances.
+type Twitter struct {
+	nitter         *nitterClient
+	username       string
+	includeReplies bool
+
+	mu sync.Mutex
+	// undated are IDs of tweets without publication time in the last fetched timeline. They can't be
+	// filtered by time, so they are returned once and skipped while they stay in the timeline.
+	undated map[string]struct{}
+}
+
+func NewTwitter(username string, instances []string, includeReplies bool) *Twitter {
+	return &Twitter{
+		nitter:         newNitterClient(instances),
+		username:       username,
+		includeReplies: includeReplies,
+	}
+}
+
+func (tw *Twitter) Fetch(ctx context.Context, since time.Time) ([]Item, error) {
+	f, instance, err := tw.nitter.feed(ctx, "/"+tw.username+"/rss")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch timeline of @%s. %w", tw.username, err)
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	undated := make(map[string]struct{})
+	result := make([]Item, 0, len(f.Items))
+	for _, it := range f.Items {
+		t, err := parseTweet(instance, it)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tweet of @%s. %w", tw.username, err)
+		}
+
+		if t.published.IsZero() {
+			_, returned := tw.undated[t.id]
+			undated[t.id] = struct{}{}
+			if returned {
+				continue
+			}
+		} else if !t.published.After(since) {
+			continue
+		}
+
+		if t.isReply && !tw.includeReplies {
+			continue
+		}
+
+		// links are expanded only for tweets which are returned
+		result = append(result, tw.nitter.tweetItem(ctx, "@"+tw.username, t))
+	}
+	tw.undated = undated
+
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is sent by Direct. It contains RobotsUserAgent, so sites can tell the crawler
+// from browsers and address it in robots.txt.
+const DefaultUserAgent = "Mozilla/5.0 (compatible; " + RobotsUserAgent + "/0.1; +https://github.com/pavelpuchok/insightcourier)"
+
+// maxPageSize limits the page body read by Direct.
+const maxPageSize = 10 << 20
+
+// directTimeout bounds a page request of Direct including reading the body, so a stalled
+// site doesn't hold the worker.
+const directTimeout = 30 * time.Second
+
+// challengeMarkers are parts of challenge pages served by Cloudflare and similar services.
+var challengeMarkers = []string{
+	"<title>just a moment...</title>",
+	"<title>attention required! | cloudflare</title>",
+	"cf-browser-verification",
+	"cf_chl_opt",
+	"/cdn-cgi/challenge-platform/",
+	"<title>ddos-guard</title>",
+	"<title>access denied</title>",
+	"_incapsula_resource",
+	"captcha-delivery.com",
+	"sucuri website firewall",
+}
+
+// challengeServers are values of the Server header of anti-bot proxies.
+var challengeServers = []string{"cloudflare", "ddos-guard", "sucuri"}
+
+// Direct gets pages with plain HTTP requests.
+type Direct struct {
+	client    *http.Client
+	userAgent string
+}
+
+func NewDirect() *Direct {
+	return &Direct{
+		client:    &http.Client{Timeout: directTimeout},
+		userAgent: DefaultUserAgent,
+	}
+}
+
+func (d *Direct) Name() string {
+	return "direct"
+}
+
+func (d *Direct) Fetch(ctx context.Context, pageURL string) (Page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return Page{}, fmt.Errorf("unable to build request. %w", err)
+	}
+	req.Header.Set("User-Agent", d.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	r, err := d.client.Do(req)
+	if err != nil {
+		return Page{}, fmt.Errorf("unable to get page %s. %w", pageURL, err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxPageSize))
+	if err != nil {
+		return Page{}, fmt.Errorf("unable to read page %s. %w", pageURL, err)
+	}
+	body := string(b)
+
+	if isChallenge(r, body) {
+		return Page{}, fmt.Errorf("%w at %s (status %d)", ErrChallenge, pageURL, r.StatusCode)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return Page{}, &StatusError{URL: pageURL, Code: r.StatusCode, RetryAfter: parseRetryAfter(r.Header.Get("Retry-After"))}
+	}
+
+	return Page{
+		URL:  r.Request.URL.String(),
+		HTML: body,
+	}, nil
+}
+
+// isChallenge detects anti-bot challenge responses.
+func isChallenge(r *http.Response, body string) bool {
+	if r.Header.Get("Cf-Mitigated") == "challenge" {
+		return true
+	}
+
+	blocked := r.StatusCode == http.StatusForbidden || r.StatusCode == http.StatusTooManyRequests || r.StatusCode == http.StatusServiceUnavailable
+	if blocked {
+		server := strings.ToLower(r.Header.Get("Server"))
+		for _, s := range challengeServers {
+			if strings.Contains(server, s) {
+				return true
+			}
+		}
+	}
+
+	// challenge pages are small, a large page mentioning a marker is likely an article about it
+	if len(body) > 256<<10 {
+		return false
+	}
+	lower := strings.ToLower(body)
+	for _, m := range challengeMarkers {
+		if strings.Contains(lower, m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrChallenge is returned when the site responds with an anti-bot challenge instead of the page.
+var ErrChallenge = errors.New("anti-bot challenge")
+
+// Page is a fetched web page.
+type Page struct {
+	// URL is the page address after redirects.
+	URL  string
+	HTML string
+}
+
+// Strategy is a way of getting web pages.
+type Strategy interface {
+	Name() string
+	Fetch(ctx context.Context, pageURL string) (Page, error)
+}
+
+// StatusError is returned when the site responds with unexpected status.
+type StatusError struct {
+	URL  string
+	Code int
+	// RetryAfter is the delay requested by the site with Retry-After header.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status for %s: %d", e.URL, e.Code)
+}
+
+// DefaultMemoryTTL is how long a strategy that succeeded is used first for its domain.
+const DefaultMemoryTTL = 24 * time.Hour
+
+type winner struct {
+	strategy int
+	at       time.Time
+}
+
+// Chain tries strategies in order until one of them gets the page. The strategy that
+// succeeded is remembered per domain and tried first next time, so protected sites
+// don't pay for a failed direct request on every page.
+type Chain struct {
+	strategies []Strategy
+	ttl        time.Duration
+	mu         sync.Mutex
+	winners    map[string]winner
+}
+
+func NewChain(strategies ...Strategy) *Chain {
+	return &Chain{
+		strategies: strategies,
+		ttl:        DefaultMemoryTTL,
+		winners:    make(map[string]winner),
+	}
+}
+
+func (c *Chain) Name() string {
+	names := make([]string, len(c.strategies))
+	for i, s := range c.strategies {
+		names[i] = s.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (c *Chain) Fetch(ctx context.Context, pageURL string) (Page, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return Page{}, fmt.Errorf("invalid page URL %s. %w", pageURL, err)
+	}
+	domain := strings.ToLower(u.Hostname())
+
+	var errs []error
+	for _, i := range c.order(domain) {
+		s := c.strategies[i]
+		page, err := s.Fetch(ctx, pageURL)
+		if err == nil {
+			c.remember(domain, i)
+			return page, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		if !fallback(err) || ctx.Err() != nil {
+			break
+		}
+		slog.Debug("Fetch strategy failed, trying next one", slog.String("strategy", s.Name()), slog.String("link", pageURL), slog.String("error", err.Error()))
+	}
+
+	return Page{}, fmt.Errorf("failed to fetch page %s. %w", pageURL, errors.Join(errs...))
+}
+
+// order returns strategy indexes to try for the domain, the remembered one goes first.
+func (c *Chain) order(domain string) []int {
+	c.mu.Lock()
+	w, has := c.winners[domain]
+	if has && time.Since(w.at) > c.ttl {
+		delete(c.winners, domain)
+		has = false
+	}
+	c.mu.Unlock()
+
+	result := make([]int, 0, len(c.strategies))
+	if has {
+		result = append(result, w.strategy)
+	}
+	for i := range c.strategies {
+		if !has || i != w.strategy {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func (c *Chain) remember(domain string, strategy int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if w, has := c.winners[domain]; has && w.strategy == strategy {
+		return
+	}
+	// the first strategy is the default one and doesn't need to be remembered
+	if strategy == 0 {
+		delete(c.winners, domain)
+		return
+	}
+	c.winners[domain] = winner{strategy: strategy, at: time.Now()}
+	slog.Info("Fetch strategy remembered for domain", slog.String("domain", domain), slog.String("strategy", c.strategies[strategy].Name()))
+}
+
+// fallback reports whether the next strategy may succeed after err. Statuses like
+// 404 Not Found are answered the same way to any client, so there is no point to retry.
+// Limiter errors apply to the host regardless of the strategy.
+func fallback(err error) bool {
+	if errors.Is(err, ErrDisallowed) || errors.Is(err, ErrThrottled) {
+		return false
+	}
+
+	var se *StatusError
+	if !errors.As(err, &se) {
+		return true
+	}
+
+	switch se.Code {
+	case 401, 403, 429, 503:
+		return true
+	}
+	return false
+}
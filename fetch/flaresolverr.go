@@ -0,0 +1,46 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+)
+
+// Solver is FlareSolverr client, either flaresolverr.FlareSolverr or flaresolverr.Breaker.
+type Solver interface {
+	Get(ctx context.Context, pageURL string, opts ...flaresolverr.Option) (*flaresolverr.GetResponse, error)
+}
+
+// FlareSolverr gets pages with a headless browser which passes anti-bot challenges.
+type FlareSolverr struct {
+	fs Solver
+}
+
+func NewFlareSolverr(fs Solver) *FlareSolverr {
+	return &FlareSolverr{fs: fs}
+}
+
+func (f *FlareSolverr) Name() string {
+	return "flaresolverr"
+}
+
+func (f *FlareSolverr) Fetch(ctx context.Context, pageURL string) (Page, error) {
+	fsResp, err := f.fs.Get(ctx, pageURL, flaresolverr.WithDisabledMedia())
+	if err != nil {
+		return Page{}, fmt.Errorf("unable to get page %s. %w", pageURL, err)
+	}
+
+	if fsResp.Status != "ok" {
+		return Page{}, fmt.Errorf("unexpected FlareSolverr status: status=%s message=%s", fsResp.Status, fsResp.Message)
+	}
+
+	if fsResp.Solution.Status >= 400 {
+		return Page{}, &StatusError{URL: pageURL, Code: fsResp.Solution.Status}
+	}
+
+	return Page{
+		URL:  fsResp.Solution.Url,
+		HTML: fsResp.Solution.Response,
+	}, nil
+}
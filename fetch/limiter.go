@@ -0,0 +1,326 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrDisallowed is returned when robots.txt of the site disallows the page.
+	ErrDisallowed = errors.New("disallowed by robots.txt")
+	// ErrThrottled is matched by ThrottledError.
+	ErrThrottled = errors.New("host is throttled")
+)
+
+// ThrottledError is returned when the host can't be requested in reasonable time.
+type ThrottledError struct {
+	Host string
+	// RetryAt is when the host may be requested again.
+	RetryAt time.Time
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%s: %s can be requested at %s", ErrThrottled, e.Host, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// RobotsUserAgent is the product token matched against robots.txt groups.
+const RobotsUserAgent = "insightcourier"
+
+const (
+	// maxSlowdown bounds how many times the host rate is reduced after 429/503 responses.
+	maxSlowdown = 32
+	// recoverAfter is how long the host should respond normally before its rate is doubled back.
+	recoverAfter = 5 * time.Minute
+	// maxRobotsSize limits the robots.txt body, larger files are truncated like Google does.
+	maxRobotsSize = 500 << 10
+	// robotsTimeout bounds robots.txt requests, pages of the host wait for them.
+	robotsTimeout = 10 * time.Second
+)
+
+// Limiter keeps request rate and concurrency of every host under the limits. It is shared by
+// strategies wrapped with it, so a host is limited regardless of the way its pages are requested.
+type Limiter struct {
+	rate        rate.Limit
+	burst       int
+	concurrency int
+	maxWait     time.Duration
+	robotsTTL   time.Duration
+	client      *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type LimiterOption = func(*Limiter)
+
+// WithRobots enables robots.txt rules, files are cached for ttl.
+func WithRobots(ttl time.Duration) LimiterOption {
+	return func(l *Limiter) {
+		l.robotsTTL = ttl
+	}
+}
+
+// WithMaxWait sets how long a request may wait for its turn before failing with ThrottledError.
+func WithMaxWait(d time.Duration) LimiterOption {
+	return func(l *Limiter) {
+		l.maxWait = d
+	}
+}
+
+// NewLimiter creates Limiter allowing perHost requests per second with burst to every host
+// and at most concurrency requests in progress.
+func NewLimiter(perHost float64, burst int, concurrency int, opts ...LimiterOption) *Limiter {
+	l := &Limiter{
+		rate:        rate.Limit(perHost),
+		burst:       burst,
+		concurrency: concurrency,
+		maxWait:     time.Minute,
+		client:      &http.Client{Timeout: robotsTimeout},
+		hosts:       make(map[string]*hostState),
+	}
+	for _, optFunc := range opts {
+		optFunc(l)
+	}
+	return l
+}
+
+type hostState struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+
+	mu          sync.Mutex
+	base        rate.Limit
+	slowdown    float64
+	slowedAt    time.Time
+	pausedUntil time.Time
+	robots      *robotstxt.Group
+	robotsAt    time.Time
+	// robotsMu is held while robots.txt is fetched, so concurrent requests to the host fetch it once
+	robotsMu sync.Mutex
+}
+
+func (l *Limiter) host(u *url.URL) *hostState {
+	key := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, has := l.hosts[key]
+	if !has {
+		h = &hostState{
+			limiter:  rate.NewLimiter(l.rate, l.burst),
+			slots:    make(chan struct{}, l.concurrency),
+			base:     l.rate,
+			slowdown: 1,
+		}
+		l.hosts[key] = h
+	}
+	return h
+}
+
+// Wrap returns strategy which requests pages through the limiter.
+func (l *Limiter) Wrap(s Strategy) Strategy {
+	return &limited{strategy: s, limiter: l}
+}
+
+type limited struct {
+	strategy Strategy
+	limiter  *Limiter
+}
+
+func (s *limited) Name() string {
+	return s.strategy.Name()
+}
+
+func (s *limited) Fetch(ctx context.Context, pageURL string) (Page, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return Page{}, fmt.Errorf("invalid page URL %s. %w", pageURL, err)
+	}
+	h := s.limiter.host(u)
+
+	if s.limiter.robotsTTL > 0 && !s.limiter.allowed(ctx, h, u) {
+		return Page{}, fmt.Errorf("%w: %s", ErrDisallowed, pageURL)
+	}
+
+	release, err := s.limiter.acquire(ctx, h, u.Host)
+	if err != nil {
+		return Page{}, err
+	}
+	defer release()
+
+	page, err := s.strategy.Fetch(ctx, pageURL)
+
+	var se *StatusError
+	if errors.As(err, &se) && (se.Code == http.StatusTooManyRequests || se.Code == http.StatusServiceUnavailable) {
+		h.slowDown(se.RetryAfter)
+		slog.Warn("Host asked to slow down", slog.String("host", u.Host), slog.Int("status", se.Code), slog.Float64("rate", float64(h.limiter.Limit())))
+	} else if err == nil {
+		h.recover()
+	}
+
+	return page, err
+}
+
+// acquire waits for a free slot and the rate limiter token of the host.
+func (l *Limiter) acquire(ctx context.Context, h *hostState, host string) (func(), error) {
+	h.mu.Lock()
+	pause := time.Until(h.pausedUntil)
+	h.mu.Unlock()
+	if pause > l.maxWait {
+		return nil, &ThrottledError{Host: host, RetryAt: time.Now().Add(pause)}
+	}
+
+	r := h.limiter.Reserve()
+	delay := max(r.Delay(), pause)
+	if delay > l.maxWait {
+		r.Cancel()
+		return nil, &ThrottledError{Host: host, RetryAt: time.Now().Add(delay)}
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+		r.Cancel()
+		return nil, ctx.Err()
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return func() { <-h.slots }, nil
+}
+
+// slowDown halves the host rate and pauses requests for retryAfter.
+func (h *hostState) slowDown(retryAfter time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.slowdown = min(h.slowdown*2, maxSlowdown)
+	h.slowedAt = now
+	h.limiter.SetLimit(h.base / rate.Limit(h.slowdown))
+	if until := now.Add(retryAfter); until.After(h.pausedUntil) {
+		h.pausedUntil = until
+	}
+}
+
+// recover doubles the rate of the slowed down host back once it responds normally for a while.
+func (h *hostState) recover() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.slowdown <= 1 || time.Since(h.slowedAt) < recoverAfter {
+		return
+	}
+	h.slowdown /= 2
+	h.slowedAt = time.Now()
+	h.limiter.SetLimit(h.base / rate.Limit(h.slowdown))
+}
+
+// allowed checks the page against robots.txt rules of its host. A missing or unavailable
+// robots.txt allows everything.
+func (l *Limiter) allowed(ctx context.Context, h *hostState, u *url.URL) bool {
+	h.robotsMu.Lock()
+	defer h.robotsMu.Unlock()
+
+	h.mu.Lock()
+	group := h.robots
+	expired := group == nil || time.Since(h.robotsAt) > l.robotsTTL
+	h.mu.Unlock()
+
+	if expired {
+		group = l.fetchRobots(ctx, u)
+
+		h.mu.Lock()
+		h.robots = group
+		h.robotsAt = time.Now()
+		h.setCrawlDelay(group.CrawlDelay)
+		h.mu.Unlock()
+	}
+
+	return group.Test(u.RequestURI())
+}
+
+// setCrawlDelay reduces the host base rate to follow the robots.txt Crawl-delay.
+func (h *hostState) setCrawlDelay(d time.Duration) {
+	base := h.base
+	if d > 0 {
+		base = min(base, rate.Every(d))
+	}
+	if base == h.base {
+		return
+	}
+	h.base = base
+	h.limiter.SetLimit(h.base / rate.Limit(h.slowdown))
+}
+
+func (l *Limiter) fetchRobots(ctx context.Context, u *url.URL) *robotstxt.Group {
+	robotsURL := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}).String()
+	allowAll := &robotstxt.Group{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return allowAll
+	}
+	req.Header.Set("User-Agent", DefaultUserAgent)
+
+	r, err := l.client.Do(req)
+	if err != nil {
+		slog.Debug("Failed to get robots.txt", slog.String("url", robotsURL), slog.String("error", err.Error()))
+		return allowAll
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxRobotsSize))
+	if err != nil {
+		return allowAll
+	}
+
+	// server errors make robotstxt disallow everything, a broken site shouldn't stop extraction
+	if r.StatusCode >= 500 {
+		return allowAll
+	}
+
+	robots, err := robotstxt.FromStatusAndBytes(r.StatusCode, b)
+	if err != nil {
+		slog.Debug("Failed to parse robots.txt", slog.String("url", robotsURL), slog.String("error", err.Error()))
+		return allowAll
+	}
+	return robots.FindGroup(RobotsUserAgent)
+}
+
+// parseRetryAfter parses the Retry-After header value given in seconds or as HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
@@ -0,0 +1,215 @@
+package flaresolverr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoInstances is returned by Balancer when all instances are ejected.
+var ErrNoInstances = errors.New("no available FlareSolverr instances")
+
+// Instance is a FlareSolverr service balanced by Balancer, Name is used in logs.
+type Instance struct {
+	Name    string
+	Service Service
+}
+
+type backend struct {
+	name     string
+	service  Service
+	inFlight int
+	failures int
+	ejected  bool
+}
+
+// route pins a domain to the instance.
+type route struct {
+	backend  *backend
+	lastUsed time.Time
+}
+
+// Balancer spreads requests over FlareSolverr instances, sending each one to the instance with
+// the least requests in progress. An instance failing ejectAfter requests in a row is ejected
+// until its health check passes. With sticky routing pages of a domain go to the same instance
+// while it's available, so sessions kept by the instance are reused. A domain is unpinned after
+// stickyIdle without requests, when the instance has likely destroyed its session, and requests
+// bypass the pinned instance while it has maxInFlight requests in progress.
+type Balancer struct {
+	backends    []*backend
+	sticky      bool
+	stickyIdle  time.Duration
+	maxInFlight int
+	ejectAfter  int
+
+	mu      sync.Mutex
+	domains map[string]*route
+	// next rotates the instance checked first, so ties are spread evenly
+	next int
+}
+
+func NewBalancer(instances []Instance, sticky bool, stickyIdle time.Duration, maxInFlight int, ejectAfter int) *Balancer {
+	b := &Balancer{
+		sticky:      sticky,
+		stickyIdle:  stickyIdle,
+		maxInFlight: maxInFlight,
+		ejectAfter:  ejectAfter,
+		domains:     make(map[string]*route),
+	}
+	for _, in := range instances {
+		b.backends = append(b.backends, &backend{name: in.Name, service: in.Service})
+	}
+	return b
+}
+
+func (b *Balancer) Get(ctx context.Context, pageURL string, opts ...Option) (*GetResponse, error) {
+	var domain string
+	if u, err := url.Parse(pageURL); err == nil {
+		domain = strings.ToLower(u.Hostname())
+	}
+
+	in, err := b.pick(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := in.service.Get(ctx, pageURL, opts...)
+	b.done(in, err)
+	return res, err
+}
+
+// pick returns the instance for the domain and counts the request as in progress.
+func (b *Balancer) pick(domain string) (*backend, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	pinned, has := b.domains[domain]
+	if has && now.Sub(pinned.lastUsed) > b.stickyIdle {
+		delete(b.domains, domain)
+		has = false
+	}
+	if has && !pinned.backend.ejected && pinned.backend.inFlight < b.maxInFlight {
+		pinned.lastUsed = now
+		pinned.backend.inFlight++
+		return pinned.backend, nil
+	}
+
+	var best *backend
+	for i := range b.backends {
+		in := b.backends[(b.next+i)%len(b.backends)]
+		if in.ejected {
+			continue
+		}
+		if best == nil || in.inFlight < best.inFlight {
+			best = in
+		}
+	}
+	if best == nil {
+		return nil, ErrNoInstances
+	}
+	b.next++
+
+	// the busy pinned instance keeps the domain, so its session is used once load drops
+	if b.sticky && domain != "" && !has {
+		b.domains[domain] = &route{backend: best, lastUsed: now}
+	}
+	best.inFlight++
+	return best, nil
+}
+
+// done finishes the request and ejects the instance after too many failures. As in Breaker,
+// only failures to talk to the service are counted.
+func (b *Balancer) done(in *backend, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	in.inFlight--
+	if err == nil {
+		in.failures = 0
+		return
+	}
+
+	in.failures++
+	if in.failures >= b.ejectAfter && !in.ejected {
+		b.eject(in, err)
+	}
+}
+
+func (b *Balancer) eject(in *backend, cause error) {
+	in.ejected = true
+	for domain, r := range b.domains {
+		if r.backend == in {
+			delete(b.domains, domain)
+		}
+	}
+	slog.Warn("FlareSolverr instance ejected", slog.String("instance", in.name), slog.String("error", cause.Error()))
+}
+
+// Health checks all instances, ejecting unhealthy ones and returning healthy ones back.
+// Idle domains are unpinned along the way. Returns error only if no instance is healthy.
+func (b *Balancer) Health(ctx context.Context) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(b.backends))
+	for i, in := range b.backends {
+		wg.Go(func() {
+			errs[i] = in.service.Health(ctx)
+		})
+	}
+	wg.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for domain, r := range b.domains {
+		if now.Sub(r.lastUsed) > b.stickyIdle {
+			delete(b.domains, domain)
+		}
+	}
+
+	healthy := 0
+	for i, in := range b.backends {
+		if errs[i] != nil {
+			if !in.ejected {
+				b.eject(in, errs[i])
+			}
+			errs[i] = fmt.Errorf("%s: %w", in.name, errs[i])
+			continue
+		}
+
+		healthy++
+		if in.ejected {
+			in.ejected = false
+			in.failures = 0
+			slog.Info("FlareSolverr instance is available again", slog.String("instance", in.name))
+		}
+	}
+
+	if healthy == 0 {
+		return fmt.Errorf("%w. %w", ErrNoInstances, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Run checks health of instances every interval until ctx is done.
+func (b *Balancer) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := b.Health(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("All FlareSolverr instances are unhealthy", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package flaresolverr_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+)
+
+// countingService counts requests and blocks them until release is closed, if it's set.
+type countingService struct {
+	mu       sync.Mutex
+	requests int
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (s *countingService) Get(ctx context.Context, pageURL string, opts ...flaresolverr.Option) (*flaresolverr.GetResponse, error) {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+
+	if s.release != nil {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	return &flaresolverr.GetResponse{Status: "ok"}, nil
+}
+
+func (s *countingService) Health(ctx context.Context) error {
+	return nil
+}
+
+func (s *countingService) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func TestBalancerSticky(t *testing.T) {
+	ctx := context.Background()
+	a, b := &countingService{}, &countingService{}
+	bal := flaresolverr.NewBalancer([]flaresolverr.Instance{{Name: "a", Service: a}, {Name: "b", Service: b}}, true, time.Minute, 2, 3)
+
+	for range 3 {
+		if _, err := bal.Get(ctx, "https://example.com/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.count() != 3 || b.count() != 0 {
+		t.Errorf("requests a=%d b=%d, want all sent to a", a.count(), b.count())
+	}
+}
+
+func TestBalancerStickyIdle(t *testing.T) {
+	ctx := context.Background()
+	a, b := &countingService{}, &countingService{}
+	idle := 20 * time.Millisecond
+	bal := flaresolverr.NewBalancer([]flaresolverr.Instance{{Name: "a", Service: a}, {Name: "b", Service: b}}, true, idle, 2, 3)
+
+	if _, err := bal.Get(ctx, "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the domain is unpinned, so the request goes to the next instance of the rotation
+	time.Sleep(2 * idle)
+	if _, err := bal.Get(ctx, "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if a.count() != 1 || b.count() != 1 {
+		t.Errorf("requests a=%d b=%d, want one request each", a.count(), b.count())
+	}
+}
+
+func TestBalancerStickyBusy(t *testing.T) {
+	ctx := context.Background()
+	a := &countingService{started: make(chan struct{}), release: make(chan struct{})}
+	b := &countingService{}
+	bal := flaresolverr.NewBalancer([]flaresolverr.Instance{{Name: "a", Service: a}, {Name: "b", Service: b}}, true, time.Minute, 1, 3)
+
+	done := make(chan struct{})
+	go func() {
+		if _, err := bal.Get(ctx, "https://example.com/1"); err != nil {
+			t.Error(err)
+		}
+		close(done)
+	}()
+	<-a.started
+
+	// the pinned instance is busy
+	if _, err := bal.Get(ctx, "https://example.com/2"); err != nil {
+		t.Fatal(err)
+	}
+	if b.count() != 1 {
+		t.Errorf("requests to b = %d, want the request bypassing busy a", b.count())
+	}
+
+	// the domain stays pinned to a
+	close(a.release)
+	<-done
+	a.release = nil
+	if _, err := bal.Get(ctx, "https://example.com/3"); err != nil {
+		t.Fatal(err)
+	}
+	if a.count() != 2 {
+		t.Errorf("requests to a = %d, want 2", a.count())
+	}
+}
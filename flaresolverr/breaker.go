@@ -0,0 +1,115 @@
+package flaresolverr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// CircuitOpenError is returned by Breaker while FlareSolverr is considered unavailable.
+type CircuitOpenError struct {
+	// RetryAt is when the service health is checked again.
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("FlareSolverr is unavailable until %s", e.RetryAt.Format(time.RFC3339))
+}
+
+// Service is FlareSolverr API guarded by Breaker, either FlareSolverr or SessionPool.
+type Service interface {
+	Get(ctx context.Context, pageURL string, opts ...Option) (*GetResponse, error)
+	Health(ctx context.Context) error
+}
+
+// Breaker stops sending requests to FlareSolverr after threshold consecutive failures. Once the
+// cooldown passes, the next request checks the service health first and is sent only if the service
+// is back. Only failures to talk to the service are counted, pages FlareSolverr failed to solve are not.
+type Breaker struct {
+	fs        Service
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(fs Service, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		fs:        fs,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+func (b *Breaker) Get(ctx context.Context, pageURL string, opts ...Option) (*GetResponse, error) {
+	if err := b.allow(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := b.fs.Get(ctx, pageURL, opts...)
+	b.record(err)
+	return res, err
+}
+
+// allow returns CircuitOpenError if the request shouldn't be sent. Only one request
+// probes the service health after the cooldown, the others are rejected meanwhile.
+func (b *Breaker) allow(ctx context.Context) error {
+	b.mu.Lock()
+	if b.openedAt.IsZero() {
+		b.mu.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	retryAt := b.openedAt.Add(b.cooldown)
+	if b.probing || now.Before(retryAt) {
+		b.mu.Unlock()
+		if !retryAt.After(now) {
+			retryAt = now.Add(b.cooldown)
+		}
+		return &CircuitOpenError{RetryAt: retryAt}
+	}
+	b.probing = true
+	b.mu.Unlock()
+
+	err := b.fs.Health(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err != nil {
+		b.openedAt = time.Now()
+		slog.Warn("FlareSolverr is still unavailable", slog.String("error", err.Error()))
+		return &CircuitOpenError{RetryAt: b.openedAt.Add(b.cooldown)}
+	}
+
+	b.openedAt = time.Time{}
+	b.failures = 0
+	slog.Info("FlareSolverr is available again")
+	return nil
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold && b.openedAt.IsZero() {
+		b.openedAt = time.Now()
+		slog.Warn("FlareSolverr is unavailable, requests are suspended",
+			slog.Int("failures", b.failures),
+			slog.Duration("cooldown", b.cooldown),
+			slog.String("error", err.Error()),
+		)
+	}
+}
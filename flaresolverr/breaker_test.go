@@ -0,0 +1,59 @@
+package flaresolverr_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+)
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	cooldown := 50 * time.Millisecond
+	b := flaresolverr.NewBreaker(srv.Client(), 2, cooldown)
+
+	if _, err := b.Get(ctx, "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.SetDown(true)
+	var open *flaresolverr.CircuitOpenError
+	for range 2 {
+		if _, err := b.Get(ctx, "https://example.com/"); err == nil || errors.As(err, &open) {
+			t.Fatalf("expected service error before threshold, got %v", err)
+		}
+	}
+
+	// requests are rejected without reaching the service until the cooldown ends
+	sent := len(srv.Requests())
+	srv.SetDown(false)
+	if _, err := b.Get(ctx, "https://example.com/"); !errors.As(err, &open) {
+		t.Fatalf("expected CircuitOpenError, got %v", err)
+	}
+	if !open.RetryAt.After(time.Now()) {
+		t.Errorf("RetryAt %s is not in the future", open.RetryAt)
+	}
+	if got := len(srv.Requests()); got != sent {
+		t.Errorf("%d requests sent while the circuit is open", got-sent)
+	}
+
+	// the probe fails while the service is still down
+	srv.SetDown(true)
+	time.Sleep(cooldown)
+	if _, err := b.Get(ctx, "https://example.com/"); !errors.As(err, &open) {
+		t.Fatalf("expected CircuitOpenError after failed probe, got %v", err)
+	}
+
+	// the probe passes once the service is back
+	srv.SetDown(false)
+	time.Sleep(cooldown)
+	if _, err := b.Get(ctx, "https://example.com/"); err != nil {
+		t.Fatalf("expected request after successful probe, got %v", err)
+	}
+	if _, err := b.Get(ctx, "https://example.com/"); err != nil {
+		t.Fatalf("expected closed circuit, got %v", err)
+	}
+}
@@ -2,31 +2,62 @@ package flaresolverr
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 )
 
+// DefaultClient is used when FlareSolverr.Client is nil. Its timeout exceeds the default
+// FlareSolverr maxTimeout of 60 seconds, so slow challenges are reported by the service itself.
+var DefaultClient = &http.Client{Timeout: 2 * time.Minute}
+
 type FlareSolverr struct {
-	URL string
+	// URL is the FlareSolverr API endpoint, like http://localhost:8191/v1.
+	URL    string
+	Client *http.Client
+	// Options are applied to every request before options of the call.
+	Options []Option
 }
 
-type getOptions struct {
+type requestOptions struct {
 	Cmd               string   `json:"cmd"`
-	URL               string   `json:"url"`
-	MaxTimeout        int      `json:"maxTimeout"`
-	Cookies           []Cookie `json:"cookies"`
-	ReturnOnlyCookies bool     `json:"returnOnlyCookies"`
-	WaitInSeconds     int      `json:"waitInSeconds"`
-	DisableMedia      bool     `json:"disableMedia"`
-	// TODO: proxy, session, tabsTillVerify
+	URL               string   `json:"url,omitempty"`
+	PostData          string   `json:"postData,omitempty"`
+	Session           string   `json:"session,omitempty"`
+	SessionTTLMinutes int      `json:"session_ttl_minutes,omitempty"`
+	MaxTimeout        int      `json:"maxTimeout,omitempty"`
+	Cookies           []Cookie `json:"cookies,omitempty"`
+	ReturnOnlyCookies bool     `json:"returnOnlyCookies,omitempty"`
+	WaitInSeconds     int      `json:"waitInSeconds,omitempty"`
+	DisableMedia      bool     `json:"disableMedia,omitempty"`
+	TabsTillVerify    int      `json:"tabs_till_verify,omitempty"`
+	Proxy             *Proxy   `json:"proxy,omitempty"`
 }
 
+// Cookie is a browser cookie. Cookies returned in the solution can be passed to the next
+// request with WithCookies as is.
 type Cookie struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain,omitempty"`
+	Path     string  `json:"path,omitempty"`
+	Expiry   float64 `json:"expiry,omitempty"`
+	HTTPOnly bool    `json:"httpOnly,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// Proxy is used by the browser to make requests. Credentials are optional.
+type Proxy struct {
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
 }
 
+// GetResponse is the result of request.get and request.post commands.
 type GetResponse struct {
 	Solution struct {
 		Url       string            `json:"url"`
@@ -44,41 +75,233 @@ type GetResponse struct {
 	Version        string `json:"version"`
 }
 
-type GetOption = func(*getOptions)
+type sessionsResponse struct {
+	Status   string   `json:"status"`
+	Message  string   `json:"message,omitempty"`
+	Session  string   `json:"session,omitempty"`
+	Sessions []string `json:"sessions,omitempty"`
+}
+
+type Option = func(*requestOptions)
 
-func WithDisabledMedia() func(o *getOptions) {
-	return func(o *getOptions) {
+func WithDisabledMedia() Option {
+	return func(o *requestOptions) {
 		o.DisableMedia = true
 	}
 }
 
-func (f FlareSolverr) Get(url string, opts ...GetOption) (*GetResponse, error) {
-	reqOptions := &getOptions{
-		Cmd: "request.get",
-		URL: url,
+// WithMaxTimeout limits time the browser spends solving the challenge.
+func WithMaxTimeout(d time.Duration) Option {
+	return func(o *requestOptions) {
+		o.MaxTimeout = int(d.Milliseconds())
+	}
+}
+
+// WithSession makes the request in the browser session created by CreateSession. The session keeps
+// cookies of solved challenges, so subsequent requests to the same site are faster.
+func WithSession(id string) Option {
+	return func(o *requestOptions) {
+		o.Session = id
+	}
+}
+
+// WithSessionTTL makes FlareSolverr recreate the session used by the request if it is older than d.
+func WithSessionTTL(d time.Duration) Option {
+	return func(o *requestOptions) {
+		o.SessionTTLMinutes = int(d.Minutes())
+	}
+}
+
+// WithProxy makes the request through the proxy. It is ignored for requests in a session,
+// the session proxy is set when it is created.
+func WithProxy(p Proxy) Option {
+	return func(o *requestOptions) {
+		o.Proxy = &p
+	}
+}
+
+// WithCookies sets cookies in the browser before the request, e.g. ones returned by the previous solution.
+func WithCookies(cookies []Cookie) Option {
+	return func(o *requestOptions) {
+		o.Cookies = cookies
+	}
+}
+
+// WithReturnOnlyCookies omits the page from the response, only cookies are returned.
+func WithReturnOnlyCookies() Option {
+	return func(o *requestOptions) {
+		o.ReturnOnlyCookies = true
+	}
+}
+
+// WithWait makes the browser wait after the challenge is solved, for pages loading content with scripts.
+func WithWait(d time.Duration) Option {
+	return func(o *requestOptions) {
+		o.WaitInSeconds = int(d.Seconds())
+	}
+}
+
+// WithTabsTillVerify sets the number of Tab presses needed to focus Turnstile checkbox.
+func WithTabsTillVerify(n int) Option {
+	return func(o *requestOptions) {
+		o.TabsTillVerify = n
+	}
+}
+
+// Get requests the page with request.get command.
+func (f FlareSolverr) Get(ctx context.Context, pageURL string, opts ...Option) (*GetResponse, error) {
+	reqOptions := f.options("request.get", opts)
+	reqOptions.URL = pageURL
+
+	res := GetResponse{}
+	if err := f.do(ctx, reqOptions, &res); err != nil {
+		return nil, fmt.Errorf("unable to make FlareSolverr get request. %w", err)
+	}
+
+	return &res, nil
+}
+
+// Post submits form to the page with request.post command.
+func (f FlareSolverr) Post(ctx context.Context, pageURL string, form url.Values, opts ...Option) (*GetResponse, error) {
+	reqOptions := f.options("request.post", opts)
+	reqOptions.URL = pageURL
+	reqOptions.PostData = form.Encode()
+
+	res := GetResponse{}
+	if err := f.do(ctx, reqOptions, &res); err != nil {
+		return nil, fmt.Errorf("unable to make FlareSolverr post request. %w", err)
+	}
+
+	return &res, nil
+}
+
+// CreateSession starts a browser instance kept until DestroySession. Session ID is generated
+// by FlareSolverr unless set with WithSession. WithProxy sets the proxy of all session requests.
+func (f FlareSolverr) CreateSession(ctx context.Context, opts ...Option) (string, error) {
+	reqOptions := f.options("sessions.create", opts)
+
+	res := sessionsResponse{}
+	if err := f.do(ctx, reqOptions, &res); err != nil {
+		return "", fmt.Errorf("unable to create FlareSolverr session. %w", err)
+	}
+	if res.Status != "ok" {
+		return "", fmt.Errorf("unexpected FlareSolverr status: status=%s message=%s", res.Status, res.Message)
+	}
+
+	return res.Session, nil
+}
+
+// ListSessions returns IDs of active sessions.
+func (f FlareSolverr) ListSessions(ctx context.Context) ([]string, error) {
+	res := sessionsResponse{}
+	if err := f.do(ctx, &requestOptions{Cmd: "sessions.list"}, &res); err != nil {
+		return nil, fmt.Errorf("unable to list FlareSolverr sessions. %w", err)
+	}
+	if res.Status != "ok" {
+		return nil, fmt.Errorf("unexpected FlareSolverr status: status=%s message=%s", res.Status, res.Message)
+	}
+
+	return res.Sessions, nil
+}
+
+// DestroySession closes the session browser instance.
+func (f FlareSolverr) DestroySession(ctx context.Context, id string) error {
+	res := sessionsResponse{}
+	if err := f.do(ctx, &requestOptions{Cmd: "sessions.destroy", Session: id}, &res); err != nil {
+		return fmt.Errorf("unable to destroy FlareSolverr session %s. %w", id, err)
+	}
+	if res.Status != "ok" {
+		return fmt.Errorf("unexpected FlareSolverr status: status=%s message=%s", res.Status, res.Message)
 	}
 
+	return nil
+}
+
+func (f FlareSolverr) options(cmd string, opts []Option) *requestOptions {
+	reqOptions := &requestOptions{Cmd: cmd}
+	for _, optFunc := range f.Options {
+		optFunc(reqOptions)
+	}
 	for _, optFunc := range opts {
 		optFunc(reqOptions)
 	}
+	return reqOptions
+}
 
-	payload, err := json.Marshal(&reqOptions)
+func (f FlareSolverr) client() *http.Client {
+	if f.Client != nil {
+		return f.Client
+	}
+	return DefaultClient
+}
+
+// do sends the command and decodes the response. FlareSolverr responds with error status
+// in the body, so the HTTP status isn't checked as long as the body is decoded.
+func (f FlareSolverr) do(ctx context.Context, reqOptions *requestOptions, res any) error {
+	payload, err := json.Marshal(reqOptions)
 	if err != nil {
-		return nil, fmt.Errorf("unable marshal getoptions. %w", err)
+		return fmt.Errorf("unable marshal request options. %w", err)
 	}
 
-	r, err := http.Post(f.URL, "application/json", bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.URL, bytes.NewReader(payload))
 	if err != nil {
-		return nil, fmt.Errorf("unable to make FlareSolverr get request. %w", err)
+		return fmt.Errorf("unable to build request. %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := f.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send %s command. %w", reqOptions.Cmd, err)
 	}
 	defer r.Body.Close()
 
-	res := GetResponse{}
-	d := json.NewDecoder(r.Body)
-	err = d.Decode(&res)
+	if err := json.NewDecoder(r.Body).Decode(res); err != nil {
+		return fmt.Errorf("unable to decode %s response (%s). %w", reqOptions.Cmd, r.Status, err)
+	}
+
+	return nil
+}
+
+// healthTimeout bounds health checks, a hanging service is as unhealthy as a stopped one.
+const healthTimeout = 10 * time.Second
+
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+// Health checks that the service is up using its /health endpoint.
+func (f FlareSolverr) Health(ctx context.Context) error {
+	u, err := url.Parse(f.URL)
 	if err != nil {
-		return nil, fmt.Errorf("unable to decode FlareSolverr get response. %w", err)
+		return fmt.Errorf("invalid FlareSolverr URL %s. %w", f.URL, err)
 	}
+	u.Path = "/health"
 
-	return &res, nil
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("unable to build health request. %w", err)
+	}
+
+	r, err := f.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to make FlareSolverr health request. %w", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected FlareSolverr health response status: %s", r.Status)
+	}
+
+	res := healthResponse{}
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		return fmt.Errorf("unable to decode FlareSolverr health response. %w", err)
+	}
+	if res.Status != "ok" {
+		return fmt.Errorf("unexpected FlareSolverr health status: %s", res.Status)
+	}
+
+	return nil
 }
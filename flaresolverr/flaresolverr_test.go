@@ -0,0 +1,183 @@
+package flaresolverr_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+	"github.com/pavelpuchok/insightcourier/flaresolverr/flaresolverrtest"
+)
+
+func newServer(t *testing.T) *flaresolverrtest.Server {
+	t.Helper()
+	srv := flaresolverrtest.NewServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSessions(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	fs := srv.Client()
+
+	generated, err := fs.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := fs.CreateSession(ctx, flaresolverr.WithSession("named"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if named != "named" {
+		t.Errorf("CreateSession with ID = %s, want named", named)
+	}
+
+	ids, err := fs.ListSessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"named", generated}
+	slices.Sort(ids)
+	slices.Sort(want)
+	if !slices.Equal(ids, want) {
+		t.Errorf("ListSessions = %v, want %v", ids, want)
+	}
+
+	srv.SetPage("https://example.com/", flaresolverrtest.Page{HTML: "<html>ok</html>"})
+	res, err := fs.Get(ctx, "https://example.com/", flaresolverr.WithSession(generated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != "ok" || res.Solution.Response != "<html>ok</html>" {
+		t.Errorf("unexpected response in session %+v", res)
+	}
+
+	if err := fs.DestroySession(ctx, generated); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.DestroySession(ctx, generated); err == nil {
+		t.Error("expected error destroying missing session")
+	}
+	if got := srv.Sessions(); !slices.Equal(got, []string{"named"}) {
+		t.Errorf("sessions after destroy = %v, want [named]", got)
+	}
+}
+
+func TestPost(t *testing.T) {
+	srv := newServer(t)
+	srv.SetPage("https://example.com/search", flaresolverrtest.Page{HTML: "<html>results</html>"})
+
+	form := url.Values{"q": {"go generics"}, "page": {"2"}}
+	res, err := srv.Client().Post(context.Background(), "https://example.com/search", form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Solution.Response != "<html>results</html>" {
+		t.Errorf("unexpected response %q", res.Solution.Response)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 || reqs[0].Cmd != "request.post" {
+		t.Fatalf("unexpected requests %+v", reqs)
+	}
+	if reqs[0].PostData != form.Encode() {
+		t.Errorf("postData = %q, want %q", reqs[0].PostData, form.Encode())
+	}
+}
+
+func TestProxy(t *testing.T) {
+	srv := newServer(t)
+	proxy := flaresolverr.Proxy{URL: "socks5://proxy:1080", Username: "user", Password: "secret"}
+
+	// client options are applied to every request
+	fs := srv.Client()
+	fs.Options = []flaresolverr.Option{flaresolverr.WithProxy(proxy)}
+	if _, err := fs.Get(context.Background(), "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.CreateSession(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range srv.Requests() {
+		if r.Proxy == nil || *r.Proxy != proxy {
+			t.Errorf("%s proxy = %+v, want %+v", r.Cmd, r.Proxy, proxy)
+		}
+	}
+}
+
+func TestCookiesRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	cookie := flaresolverr.Cookie{
+		Name:     "cf_clearance",
+		Value:    "token",
+		Domain:   ".example.com",
+		Path:     "/",
+		Expiry:   1767225600,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "None",
+	}
+	srv.SetPage("https://example.com/", flaresolverrtest.Page{HTML: "<html>ok</html>", Cookies: []flaresolverr.Cookie{cookie}})
+
+	fs := srv.Client()
+	res, err := fs.Get(ctx, "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Solution.Cookies) != 1 || res.Solution.Cookies[0] != cookie {
+		t.Fatalf("solution cookies = %+v, want %+v", res.Solution.Cookies, cookie)
+	}
+
+	if _, err := fs.Get(ctx, "https://example.com/next", flaresolverr.WithCookies(res.Solution.Cookies)); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.Requests()
+	if sent := reqs[len(reqs)-1].Cookies; len(sent) != 1 || sent[0] != cookie {
+		t.Errorf("sent cookies = %+v, want %+v", sent, cookie)
+	}
+
+	// FlareSolverr passes cookies to Selenium, which names the expiration time expiry
+	b, err := json.Marshal(cookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"expiry":1767225600`) {
+		t.Errorf("cookie JSON %s has no expiry", b)
+	}
+}
+
+func TestChallengeError(t *testing.T) {
+	srv := newServer(t)
+	srv.SetPage("https://example.com/", flaresolverrtest.Page{Error: "Error solving the challenge. Timeout after 60.0 seconds."})
+
+	res, err := srv.Client().Get(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != "error" || !strings.Contains(res.Message, "Timeout") {
+		t.Errorf("unexpected response %+v", res)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	srv := newServer(t)
+	fs := srv.Client()
+
+	if err := fs.Health(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.SetDown(true)
+	if err := fs.Health(context.Background()); err == nil {
+		t.Error("expected health error of stopped service")
+	}
+	if _, err := fs.Get(context.Background(), "https://example.com/"); err == nil {
+		t.Error("expected request error of stopped service")
+	}
+}
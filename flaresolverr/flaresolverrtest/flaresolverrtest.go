@@ -0,0 +1,199 @@
+// Package flaresolverrtest provides a fake FlareSolverr service for testing code using
+// the flaresolverr client without a headless browser.
+package flaresolverrtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+)
+
+// Page is served by Server for the requested URL.
+type Page struct {
+	Status  int
+	HTML    string
+	Cookies []flaresolverr.Cookie
+	// Error makes the command fail with the message, like a challenge FlareSolverr couldn't solve.
+	Error string
+}
+
+// Request is a command received by Server.
+type Request struct {
+	Cmd      string                `json:"cmd"`
+	URL      string                `json:"url"`
+	PostData string                `json:"postData"`
+	Session  string                `json:"session"`
+	Cookies  []flaresolverr.Cookie `json:"cookies"`
+	Proxy    *flaresolverr.Proxy   `json:"proxy"`
+}
+
+// Server is a fake FlareSolverr serving pages set with SetPage. Unknown pages are served with 404 status.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	pages    map[string]Page
+	sessions map[string]struct{}
+	requests []Request
+	down     bool
+	nextID   int
+}
+
+// NewServer starts Server, it should be closed with Close.
+func NewServer() *Server {
+	s := &Server{
+		pages:    make(map[string]Page),
+		sessions: make(map[string]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1", s.handleCommand)
+	mux.HandleFunc("GET /health", s.handleHealth)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Endpoint returns the API URL to be used as flaresolverr.FlareSolverr URL.
+func (s *Server) Endpoint() string {
+	return s.URL + "/v1"
+}
+
+// Client returns FlareSolverr client of the server.
+func (s *Server) Client() *flaresolverr.FlareSolverr {
+	return &flaresolverr.FlareSolverr{URL: s.Endpoint(), Client: s.Server.Client()}
+}
+
+func (s *Server) SetPage(pageURL string, p Page) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[pageURL] = p
+}
+
+// SetDown makes the server respond with errors which aren't valid API responses,
+// like a stopped service behind a proxy.
+func (s *Server) SetDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+// Requests returns commands received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Sessions returns IDs of active sessions.
+func (s *Server) Sessions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessionIDs()
+}
+
+func (s *Server) sessionIDs() []string {
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	down := s.down
+	s.mu.Unlock()
+
+	if down {
+		http.Error(w, "service unavailable", http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.down {
+		http.Error(w, "service unavailable", http.StatusBadGateway)
+		return
+	}
+	s.requests = append(s.requests, req)
+
+	switch req.Cmd {
+	case "request.get", "request.post":
+		s.handleRequest(w, req)
+	case "sessions.create":
+		id := req.Session
+		if id == "" {
+			s.nextID++
+			id = "session-" + strconv.Itoa(s.nextID)
+		}
+		s.sessions[id] = struct{}{}
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "message": "Session created successfully.", "session": id})
+	case "sessions.list":
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "sessions": s.sessionIDs()})
+	case "sessions.destroy":
+		if _, has := s.sessions[req.Session]; !has {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "Error: The session doesn't exist."})
+			return
+		}
+		delete(s.sessions, req.Session)
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "message": "The session has been removed."})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": fmt.Sprintf("Error: Request parameter 'cmd' = '%s' is invalid.", req.Cmd)})
+	}
+}
+
+func (s *Server) handleRequest(w http.ResponseWriter, req Request) {
+	if req.Session != "" {
+		if _, has := s.sessions[req.Session]; !has {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": "Error: The session doesn't exist."})
+			return
+		}
+	}
+
+	p, has := s.pages[req.URL]
+	if !has {
+		p = Page{Status: http.StatusNotFound}
+	}
+	if p.Error != "" {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "message": p.Error})
+		return
+	}
+	if p.Status == 0 {
+		p.Status = http.StatusOK
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  "ok",
+		"message": "Challenge not detected!",
+		"session": req.Session,
+		"solution": map[string]any{
+			"url":       req.URL,
+			"status":    p.Status,
+			"cookies":   p.Cookies,
+			"userAgent": "Mozilla/5.0 (flaresolverrtest)",
+			"headers":   map[string]string{},
+			"response":  p.HTML,
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
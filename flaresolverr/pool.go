@@ -0,0 +1,229 @@
+package flaresolverr
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// destroyTimeout bounds destroying of sessions on shutdown.
+const destroyTimeout = 10 * time.Second
+
+type session struct {
+	id       string
+	uses     int
+	lastUsed time.Time
+	busy     bool
+}
+
+// SessionPool keeps a FlareSolverr session per domain, so the challenge solved for the first page
+// isn't solved again for the following pages of the same site. A session serves one request at a time,
+// concurrent requests to the busy domain are made without session. Sessions are replaced after
+// maxUses requests or a failed challenge and destroyed after being idle for idleTimeout.
+type SessionPool struct {
+	fs          *FlareSolverr
+	maxSessions int
+	maxUses     int
+	idleTimeout time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	// closed is set once Run is stopped, sessions aren't kept anymore
+	closed bool
+}
+
+func NewSessionPool(fs *FlareSolverr, maxSessions int, maxUses int, idleTimeout time.Duration) *SessionPool {
+	return &SessionPool{
+		fs:          fs,
+		maxSessions: maxSessions,
+		maxUses:     maxUses,
+		idleTimeout: idleTimeout,
+		sessions:    make(map[string]*session),
+	}
+}
+
+// Get requests the page in the session of its domain.
+func (p *SessionPool) Get(ctx context.Context, pageURL string, opts ...Option) (*GetResponse, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Hostname() == "" {
+		return p.fs.Get(ctx, pageURL, opts...)
+	}
+	domain := strings.ToLower(u.Hostname())
+
+	s, err := p.acquire(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return p.fs.Get(ctx, pageURL, opts...)
+	}
+
+	res, err := p.fs.Get(ctx, pageURL, append(opts, WithSession(s.id))...)
+	p.release(ctx, domain, s, err == nil && solved(res))
+	return res, err
+}
+
+func (p *SessionPool) Health(ctx context.Context) error {
+	return p.fs.Health(ctx)
+}
+
+// solved reports whether the session passed the site challenge, otherwise it shouldn't be reused.
+func solved(res *GetResponse) bool {
+	if res.Status != "ok" {
+		return false
+	}
+
+	switch res.Solution.Status {
+	case http.StatusForbidden, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return false
+	}
+	return true
+}
+
+// acquire returns the domain session marked busy, creating it if needed. Returns nil session
+// if the request should be made without session.
+func (p *SessionPool) acquire(ctx context.Context, domain string) (*session, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, nil
+	}
+	s, has := p.sessions[domain]
+	if has && s.busy {
+		p.mu.Unlock()
+		return nil, nil
+	}
+
+	var expired []string
+	if has && (s.uses >= p.maxUses || time.Since(s.lastUsed) > p.idleTimeout) {
+		delete(p.sessions, domain)
+		expired = append(expired, s.id)
+		has = false
+	}
+
+	if !has && len(p.sessions) >= p.maxSessions {
+		lru := p.leastRecentlyUsed()
+		if lru == "" {
+			p.mu.Unlock()
+			p.destroy(ctx, expired...)
+			return nil, nil
+		}
+		expired = append(expired, p.sessions[lru].id)
+		delete(p.sessions, lru)
+	}
+
+	if has {
+		s.busy = true
+		s.uses++
+		s.lastUsed = time.Now()
+		p.mu.Unlock()
+		p.destroy(ctx, expired...)
+		return s, nil
+	}
+
+	// the session is reserved before it's created, so concurrent requests don't create another one
+	s = &session{busy: true, uses: 1, lastUsed: time.Now()}
+	p.sessions[domain] = s
+	p.mu.Unlock()
+	p.destroy(ctx, expired...)
+
+	id, err := p.fs.CreateSession(ctx)
+	if err != nil {
+		p.mu.Lock()
+		delete(p.sessions, domain)
+		p.mu.Unlock()
+		return nil, err
+	}
+	slog.Debug("FlareSolverr session created", slog.String("domain", domain), slog.String("session", id))
+
+	p.mu.Lock()
+	s.id = id
+	p.mu.Unlock()
+	return s, nil
+}
+
+// leastRecentlyUsed returns the domain of the least recently used idle session.
+func (p *SessionPool) leastRecentlyUsed() string {
+	var domain string
+	var lastUsed time.Time
+	for d, s := range p.sessions {
+		if s.busy {
+			continue
+		}
+		if domain == "" || s.lastUsed.Before(lastUsed) {
+			domain = d
+			lastUsed = s.lastUsed
+		}
+	}
+	return domain
+}
+
+// release returns the session to the pool, or destroys it if the request failed.
+func (p *SessionPool) release(ctx context.Context, domain string, s *session, ok bool) {
+	p.mu.Lock()
+	s.busy = false
+	if ok && !p.closed {
+		p.mu.Unlock()
+		return
+	}
+	if p.sessions[domain] == s {
+		delete(p.sessions, domain)
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		slog.Debug("FlareSolverr session failed, it will be recreated", slog.String("domain", domain), slog.String("session", s.id))
+	}
+	p.destroy(ctx, s.id)
+}
+
+func (p *SessionPool) destroy(ctx context.Context, ids ...string) {
+	for _, id := range ids {
+		if err := p.fs.DestroySession(ctx, id); err != nil {
+			slog.Debug("Failed to destroy FlareSolverr session", slog.String("session", id), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Run destroys idle sessions until ctx is done, then destroys all sessions. Sessions in use
+// are destroyed once their requests are finished.
+func (p *SessionPool) Run(ctx context.Context) {
+	t := time.NewTicker(max(p.idleTimeout/2, time.Second))
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.mu.Lock()
+			p.closed = true
+			p.mu.Unlock()
+
+			dctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), destroyTimeout)
+			defer cancel()
+			p.destroy(dctx, p.take(func(*session) bool { return true })...)
+			return
+		case <-t.C:
+			p.destroy(ctx, p.take(func(s *session) bool { return time.Since(s.lastUsed) > p.idleTimeout })...)
+		}
+	}
+}
+
+// take removes idle sessions matching fn from the pool and returns their IDs.
+func (p *SessionPool) take(fn func(*session) bool) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var ids []string
+	for domain, s := range p.sessions {
+		if s.busy || !fn(s) {
+			continue
+		}
+		delete(p.sessions, domain)
+		ids = append(ids, s.id)
+	}
+	return ids
+}
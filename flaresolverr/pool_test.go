@@ -0,0 +1,144 @@
+package flaresolverr_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/flaresolverr"
+	"github.com/pavelpuchok/insightcourier/flaresolverr/flaresolverrtest"
+)
+
+// lastSession returns session of the last page request.
+func lastSession(t *testing.T, srv *flaresolverrtest.Server) string {
+	t.Helper()
+	reqs := srv.Requests()
+	for i := len(reqs) - 1; i >= 0; i-- {
+		if reqs[i].Cmd == "request.get" {
+			return reqs[i].Session
+		}
+	}
+	t.Fatal("no page requests")
+	return ""
+}
+
+func TestSessionPoolReuse(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	p := flaresolverr.NewSessionPool(srv.Client(), 2, 2, time.Minute)
+
+	if _, err := p.Get(ctx, "https://a.example/1"); err != nil {
+		t.Fatal(err)
+	}
+	first := lastSession(t, srv)
+	if first == "" {
+		t.Fatal("page isn't requested in session")
+	}
+
+	if _, err := p.Get(ctx, "https://A.example/2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := lastSession(t, srv); got != first {
+		t.Errorf("session %s isn't reused, got %s", first, got)
+	}
+
+	// the session is replaced after maxUses requests
+	if _, err := p.Get(ctx, "https://a.example/3"); err != nil {
+		t.Fatal(err)
+	}
+	if got := lastSession(t, srv); got == first {
+		t.Error("session isn't replaced after max uses")
+	}
+	if sessions := srv.Sessions(); len(sessions) != 1 {
+		t.Errorf("sessions = %v, want the replacement only", sessions)
+	}
+}
+
+func TestSessionPoolLimit(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	p := flaresolverr.NewSessionPool(srv.Client(), 1, 10, time.Minute)
+
+	if _, err := p.Get(ctx, "https://a.example/"); err != nil {
+		t.Fatal(err)
+	}
+	a := lastSession(t, srv)
+
+	// the least recently used session gives way to the new domain
+	if _, err := p.Get(ctx, "https://b.example/"); err != nil {
+		t.Fatal(err)
+	}
+	b := lastSession(t, srv)
+	if b == "" || b == a {
+		t.Fatalf("b.example session = %q, want new session", b)
+	}
+	if sessions := srv.Sessions(); len(sessions) != 1 || sessions[0] != b {
+		t.Errorf("sessions = %v, want [%s]", sessions, b)
+	}
+}
+
+func TestSessionPoolFailedChallenge(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	p := flaresolverr.NewSessionPool(srv.Client(), 2, 10, time.Minute)
+	srv.SetPage("https://a.example/blocked", flaresolverrtest.Page{Status: http.StatusForbidden})
+
+	if _, err := p.Get(ctx, "https://a.example/blocked"); err != nil {
+		t.Fatal(err)
+	}
+	blocked := lastSession(t, srv)
+	if sessions := srv.Sessions(); len(sessions) != 0 {
+		t.Errorf("session of failed challenge isn't destroyed, sessions = %v", sessions)
+	}
+
+	if _, err := p.Get(ctx, "https://a.example/"); err != nil {
+		t.Fatal(err)
+	}
+	if got := lastSession(t, srv); got == blocked {
+		t.Error("session of failed challenge is reused")
+	}
+}
+
+func TestSessionPoolRun(t *testing.T) {
+	srv := newServer(t)
+	idle := 100 * time.Millisecond
+	p := flaresolverr.NewSessionPool(srv.Client(), 2, 10, idle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	if _, err := p.Get(ctx, "https://a.example/"); err != nil {
+		t.Fatal(err)
+	}
+
+	// idle sessions are destroyed by the ticker, which runs every second at least
+	deadline := time.Now().Add(3 * time.Second)
+	for len(srv.Sessions()) != 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if sessions := srv.Sessions(); len(sessions) != 0 {
+		t.Errorf("idle sessions aren't destroyed, sessions = %v", sessions)
+	}
+
+	if _, err := p.Get(ctx, "https://b.example/"); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	<-done
+	if sessions := srv.Sessions(); len(sessions) != 0 {
+		t.Errorf("sessions aren't destroyed on shutdown, sessions = %v", sessions)
+	}
+
+	// requests after shutdown are made without session
+	if _, err := p.Get(context.Background(), "https://c.example/"); err != nil {
+		t.Fatal(err)
+	}
+	if got := lastSession(t, srv); got != "" {
+		t.Errorf("request after shutdown is made in session %s", got)
+	}
+}
@@ -4,25 +4,151 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/pavelpuchok/insightcourier/canonical"
 	"github.com/pavelpuchok/insightcourier/config"
 	"github.com/pavelpuchok/insightcourier/feed"
+	"github.com/pavelpuchok/insightcourier/fetch"
 	"github.com/pavelpuchok/insightcourier/flaresolverr"
 	"github.com/pavelpuchok/insightcourier/planner"
 	"github.com/pavelpuchok/insightcourier/storage"
+	"github.com/pavelpuchok/insightcourier/storage/psql"
 	"github.com/pavelpuchok/insightcourier/tg"
 )
 
+type sourceDef struct {
+	fetcher  Fetcher
+	pages    PageFetcher
+	schedule planner.Schedule
+	onUpdate string
+	adaptive *AdaptivePolling
+	// retention of seen items, zero to keep them forever
+	retention time.Duration
+}
+
+// newFlareSolverr creates FlareSolverr client applying configured limits and proxy to every request.
+func newFlareSolverr(cfg config.FlareSolverrConfig, endpoint string) *flaresolverr.FlareSolverr {
+	fs := &flaresolverr.FlareSolverr{
+		URL:     endpoint,
+		Client:  &http.Client{Timeout: cfg.Timeout},
+		Options: []flaresolverr.Option{flaresolverr.WithMaxTimeout(cfg.MaxTimeout)},
+	}
+	if cfg.ProxyURL != "" {
+		fs.Options = append(fs.Options, flaresolverr.WithProxy(flaresolverr.Proxy{
+			URL:      cfg.ProxyURL,
+			Username: cfg.ProxyUsername,
+			Password: cfg.ProxyPassword,
+		}))
+	}
+	return fs
+}
+
+// newFlareSolverrInstances creates clients of all configured instances. Unless sessions are
+// disabled, every instance gets its own session pool, which should be run by the caller.
+func newFlareSolverrInstances(cfg config.FlareSolverrConfig) ([]flaresolverr.Instance, []*flaresolverr.SessionPool) {
+	instances := make([]flaresolverr.Instance, 0, len(cfg.URLs))
+	var pools []*flaresolverr.SessionPool
+	for _, u := range cfg.URLs {
+		fs := newFlareSolverr(cfg, u)
+		var service flaresolverr.Service = fs
+		if !cfg.DisableSessions {
+			pool := flaresolverr.NewSessionPool(fs, cfg.MaxSessions, cfg.SessionMaxUses, cfg.SessionIdleTimeout)
+			pools = append(pools, pool)
+			service = pool
+		}
+		instances = append(instances, flaresolverr.Instance{Name: u, Service: service})
+	}
+	return instances, pools
+}
+
+// httpCacheStore keeps HTTP cache of RSS feeds in the storage.
+type httpCacheStore struct {
+	storage *storage.PostgreSQL
+}
+
+func (c httpCacheStore) GetHTTPCache(ctx context.Context, source string) (feed.HTTPCache, error) {
+	hc, err := c.storage.GetHTTPCache(ctx, source)
+	return feed.HTTPCache(hc), err
+}
+
+func (c httpCacheStore) SetHTTPCache(ctx context.Context, source string, hc feed.HTTPCache) error {
+	return c.storage.SetHTTPCache(ctx, source, storage.HTTPCache(hc))
+}
+
+// pageFetchers are shared by all sources, so the auto chain remembers strategies of domains
+// regardless of the source that links to them and hosts are limited across all workers.
+type pageFetchers struct {
+	direct       fetch.Strategy
+	flareSolverr fetch.Strategy
+	auto         *fetch.Chain
+}
+
+func newPageFetchers(fs fetch.Solver, cfg config.PolitenessConfig) pageFetchers {
+	opts := []fetch.LimiterOption{fetch.WithMaxWait(cfg.MaxWait)}
+	if !cfg.IgnoreRobots {
+		opts = append(opts, fetch.WithRobots(cfg.RobotsTTL))
+	}
+	limiter := fetch.NewLimiter(cfg.Rate, cfg.Burst, cfg.Concurrency, opts...)
+
+	direct := limiter.Wrap(fetch.NewDirect())
+	solver := limiter.Wrap(fetch.NewFlareSolverr(fs))
+	return pageFetchers{
+		direct:       direct,
+		flareSolverr: solver,
+		auto:         fetch.NewChain(direct, solver),
+	}
+}
+
+// get returns the fetcher of config.FetchStrategy* strategy, empty strategy means auto.
+func (f pageFetchers) get(strategy string) PageFetcher {
+	switch strategy {
+	case config.FetchStrategyDirect:
+		return f.direct
+	case config.FetchStrategyFlareSolverr:
+		return f.flareSolverr
+	default:
+		return f.auto
+	}
+}
+
+// sourceSchedule returns the cron schedule if spec is set, the update interval schedule otherwise.
+func sourceSchedule(spec string, interval time.Duration) planner.Schedule {
+	if spec == "" {
+		return planner.Every(interval)
+	}
+
+	// spec is already validated by config.Load
+	schedule, err := planner.Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
 func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	cfgPath := flag.String("config", os.Getenv("IC_CONFIG_PATH"), "path to config file")
 	flag.Parse()
 
+	switch flag.Arg(0) {
+	case "discover":
+		if err := runDiscover(ctx, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if *cfgPath == "" {
 		flag.PrintDefaults()
 		os.Exit(1)
@@ -38,17 +164,188 @@ func main() {
 		panic(err)
 	}
 
+	switch flag.Arg(0) {
+	case "opml-import":
+		if err := runOPMLImport(ctx, s, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "opml-export":
+		if err := runOPMLExport(ctx, s, cfg, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "dead-list":
+		if err := runDeadList(ctx, s, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "dead-retry":
+		if err := runDeadRetry(ctx, s, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "schedule":
+		if err := runSchedule(ctx, s, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "dead-skip":
+		if err := runDeadSkip(ctx, s, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	bot, err := tg.NewBot(s, cfg.Telegram)
 	if err != nil {
 		panic(err)
 	}
 	go bot.ListenUpdates(ctx)
 
-	queue := make(chan Job)
+	queue := &JobQueue{
+		Storage: s,
+		Config:  cfg.JobQueue,
+		Retry:   cfg.Retry,
+	}
+
+	p := &planner.InMemoryPlanner{
+		Jitter:  cfg.Planner.Jitter,
+		Stagger: cfg.Planner.Stagger,
+		Store:   s,
+	}
+
+	instances, sessions := newFlareSolverrInstances(cfg.FlareSolverr)
+	// pages of a domain are sent to the same instance to reuse its session, unless the instance
+	// already solves as many pages as it keeps sessions
+	balancer := flaresolverr.NewBalancer(instances, !cfg.FlareSolverr.DisableSessions, cfg.FlareSolverr.SessionIdleTimeout,
+		cfg.FlareSolverr.MaxSessions, cfg.FlareSolverr.EjectAfter)
+	solver := flaresolverr.NewBreaker(balancer, cfg.FlareSolverr.FailureThreshold, cfg.FlareSolverr.Cooldown)
+	pages := newPageFetchers(solver, cfg.Politeness)
+
+	sources := make(map[string]sourceDef, len(cfg.RSSSources)+len(cfg.RedditSources))
+	var discoverSolver feed.Solver
+	if len(cfg.FlareSolverr.URLs) > 0 {
+		discoverSolver = solver
+	}
+	discoverer := feed.NewDiscoverer(discoverSolver)
+	for name, src := range cfg.RSSSources {
+		feedURL := src.FeedURL
+		if feedURL == "" {
+			feedURL, err = discoverFeedURL(ctx, s, discoverer, name, src.SiteURL)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		def := sourceDef{
+			fetcher:  feed.NewRSS(feedURL, feed.WithHTTPCache(name, httpCacheStore{s})),
+			pages:    pages.get(src.FetchStrategy),
+			schedule: sourceSchedule(src.Schedule, src.UpdateInterval),
+			onUpdate: src.OnUpdate,
+		}
+		if src.Adaptive {
+			def.adaptive, err = newAdaptivePolling(ctx, s, name, src.UpdateInterval, src.MinInterval, src.MaxInterval)
+			if err != nil {
+				panic(err)
+			}
+			def.schedule = def.adaptive.Schedule
+		}
+		sources[name] = def
+	}
+
+	redditOpts := []feed.RedditOption{}
+	if cfg.Reddit.BaseURL != "" {
+		redditOpts = append(redditOpts, feed.WithRedditBaseURL(cfg.Reddit.BaseURL))
+	}
+	if cfg.Reddit.UserAgent != "" {
+		redditOpts = append(redditOpts, feed.WithRedditUserAgent(cfg.Reddit.UserAgent))
+	}
+	for name, src := range cfg.RedditSources {
+		sources[name] = sourceDef{
+			fetcher:  feed.NewReddit(src.Subreddit, src.Sort, src.TimeWindow, src.Limit, redditOpts...),
+			pages:    pages.get(src.FetchStrategy),
+			schedule: sourceSchedule(src.Schedule, src.UpdateInterval),
+		}
+	}
+
+	for name, src := range cfg.RedditUsers {
+		sources[name] = sourceDef{
+			fetcher:  feed.NewRedditUser(src.Username, src.IncludeComments, src.Subreddits, src.MinScore, src.ScoreWindow, redditOpts...),
+			pages:    pages.get(src.FetchStrategy),
+			schedule: sourceSchedule(src.Schedule, src.UpdateInterval),
+		}
+	}
+
+	for name, src := range cfg.TwitterSources {
+		sources[name] = sourceDef{
+			fetcher:  feed.NewTwitter(src.Username, src.Instances, src.IncludeReplies),
+			pages:    pages.get(src.FetchStrategy),
+			schedule: sourceSchedule(src.Schedule, src.UpdateInterval),
+		}
+	}
 
-	p := &planner.InMemoryPlanner{}
+	for name, src := range cfg.QuerySources {
+		var searcher feed.Searcher
+		switch src.Provider {
+		case config.QueryProviderTwitter:
+			searcher = feed.NewNitterSearch(src.Instances)
+		case config.QueryProviderReddit:
+			searcher = feed.NewRedditSearch(src.Subreddit, src.Limit, redditOpts...)
+		case config.QueryProviderHackerNews:
+			searcher = feed.NewHackerNewsSearch(src.Limit)
+		}
+		sources[name] = sourceDef{
+			fetcher:   feed.NewQuery(searcher, src.Query, src.Retention),
+			pages:     pages.get(src.FetchStrategy),
+			schedule:  sourceSchedule(src.Schedule, src.UpdateInterval),
+			retention: src.Retention,
+		}
+	}
+
+	for name, src := range cfg.ScraperSources {
+		selectors := feed.ScraperSelectors{
+			Item:       src.ItemSelector,
+			Title:      src.TitleSelector,
+			Link:       src.LinkSelector,
+			Date:       src.DateSelector,
+			DateLayout: src.DateLayout,
+		}
+		sources[name] = sourceDef{
+			fetcher:  feed.NewScraper(src.URL, selectors, solver),
+			pages:    pages.get(src.FetchStrategy),
+			schedule: sourceSchedule(src.Schedule, src.UpdateInterval),
+		}
+	}
+
+	stored, err := s.ListFeedSources(ctx)
+	if err != nil {
+		panic(err)
+	}
+	for _, src := range stored {
+		if _, has := sources[src.Name]; has {
+			slog.Warn("Stored source is overridden by config", slog.String("source.name", src.Name))
+			continue
+		}
 
-	for name := range cfg.RSSSources {
+		interval := src.UpdateInterval
+		if interval == 0 {
+			interval = config.DefaultRSSUpdateInterval
+		}
+		sources[src.Name] = sourceDef{
+			fetcher:  feed.NewRSS(src.FeedURL, feed.WithHTTPCache(src.Name, httpCacheStore{s})),
+			pages:    pages.auto,
+			schedule: planner.Every(interval),
+		}
+	}
+
+	for name := range sources {
 		id, err := s.CreateSource(ctx, name)
 		if err != nil {
 			if errors.Is(err, storage.ErrSourceAlreadyExists) {
@@ -61,24 +358,56 @@ func main() {
 	}
 
 	w := &Worker{
-		Queue:       queue,
-		Storage:     s,
-		Reporter:    bot,
-		Fetchers:    make(map[string]Fetcher, len(cfg.RSSSources)),
-		FlareSolver: &flaresolverr.FlareSolverr{URL: cfg.FlareSolverr.URL},
+		Queue:               queue,
+		Storage:             s,
+		Sources:             make(map[string]Source, len(sources)),
+		Canonicalizer:       canonical.New(),
+		Dedup:               cfg.Dedup,
+		OnSolverUnavailable: cfg.FlareSolverr.OnUnavailable,
 	}
 
-	for name, src := range cfg.RSSSources {
-		w.Fetchers[name] = feed.NewRSS(src.FeedURL)
+	for name, src := range sources {
+		w.Sources[name] = Source{
+			Fetcher:   src.fetcher,
+			Pages:     src.pages,
+			OnUpdate:  src.onUpdate,
+			Adaptive:  src.adaptive,
+			Retention: src.retention,
+		}
 		enqeueJob := func() {
-			queue <- Job{
+			queued, err := queue.Push(ctx, storage.Job{
+				Type:       psql.JobsTypeFetchSource,
 				SourceName: name,
+			})
+			if err != nil {
+				slog.Error("Failed to enqueue source job", slog.String("source.name", name), slog.String("error", err.Error()))
+				return
+			}
+			if !queued {
+				slog.Debug("Source job is already pending", slog.String("source.name", name))
 			}
 		}
 
-		p.AddJob(context.Background(), src.UpdateInterval, enqeueJob)
+		p.AddJob(ctx, name, src.schedule, enqeueJob)
+	}
+
+	d := &Dispatcher{
+		Storage:  s,
+		Reporter: bot,
+		Config:   cfg.JobQueue,
+		Retry:    cfg.Retry,
+	}
+	var wg sync.WaitGroup
+	wg.Go(func() { d.Run(ctx) })
+	wg.Go(func() { balancer.Run(ctx, cfg.FlareSolverr.HealthCheckInterval) })
+	for _, pool := range sessions {
+		wg.Go(func() { pool.Run(ctx) })
+	}
+	for range cfg.Workers {
+		wg.Go(func() { w.Process(ctx) })
 	}
 
-	go w.Process(context.Background())
 	<-ctx.Done()
+	slog.Info("Shutting down, waiting for jobs in progress")
+	wg.Wait()
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pavelpuchok/insightcourier/config"
+	"github.com/pavelpuchok/insightcourier/feed"
+	"github.com/pavelpuchok/insightcourier/opml"
+	"github.com/pavelpuchok/insightcourier/storage"
+)
+
+// runOPMLImport implements "opml-import" subcommand which creates feed sources from OPML file.
+func runOPMLImport(ctx context.Context, s *storage.PostgreSQL, args []string) error {
+	fset := flag.NewFlagSet("opml-import", flag.ExitOnError)
+	interval := fset.Duration("interval", config.DefaultRSSUpdateInterval, "update interval of imported sources")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: insightcourier opml-import [flags] <file.opml>")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	if fset.NArg() != 1 {
+		fset.Usage()
+		return errors.New("OPML file should be provided")
+	}
+
+	f, err := os.Open(fset.Arg(0))
+	if err != nil {
+		return fmt.Errorf("unable to open OPML file. %w", err)
+	}
+	defer f.Close()
+
+	o, err := opml.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range o.Feeds() {
+		name := feed.Title
+		if name == "" {
+			u, err := url.Parse(feed.FeedURL)
+			if err != nil {
+				return fmt.Errorf("invalid feed URL %s. %w", feed.FeedURL, err)
+			}
+			name = u.Host
+		}
+
+		id, err := s.CreateFeedSource(ctx, storage.FeedSource{
+			Name:           name,
+			FeedURL:        feed.FeedURL,
+			UpdateInterval: *interval,
+			Category:       feed.Category,
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrSourceAlreadyExists) {
+				slog.Warn("Source already exists, skipped", slog.String("source.name", name), slog.String("source.feedUrl", feed.FeedURL))
+				continue
+			}
+			return err
+		}
+		slog.Info("Source imported", slog.String("source.name", name), slog.Int("source.id", int(id)))
+	}
+
+	return nil
+}
+
+// runOPMLExport implements "opml-export" subcommand which writes all RSS sources, from both config
+// and storage, sorted by name as OPML to the file or stdout.
+func runOPMLExport(ctx context.Context, s *storage.PostgreSQL, cfg *config.Config, args []string) error {
+	fset := flag.NewFlagSet("opml-export", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: insightcourier opml-export [file.opml]")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if fset.NArg() > 0 {
+		f, err := os.Create(fset.Arg(0))
+		if err != nil {
+			return fmt.Errorf("unable to create OPML file. %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var fs feed.Solver
+	if len(cfg.FlareSolverr.URLs) > 0 {
+		fs = newFlareSolverr(cfg.FlareSolverr, cfg.FlareSolverr.URLs[0])
+	}
+	discoverer := feed.NewDiscoverer(fs)
+
+	var feeds []opml.Feed
+	for name, src := range cfg.RSSSources {
+		// sources configured with site URL only are exported with the feed they use
+		feedURL := src.FeedURL
+		if feedURL == "" {
+			candidates, err := discoverer.Discover(ctx, src.SiteURL)
+			if err != nil {
+				slog.Warn("Failed to discover source feed, skipped", slog.String("source.name", name), slog.String("error", err.Error()))
+				continue
+			}
+			feedURL = candidates[0].URL
+		}
+
+		feeds = append(feeds, opml.Feed{
+			Title:   name,
+			FeedURL: feedURL,
+			SiteURL: src.SiteURL,
+		})
+	}
+
+	stored, err := s.ListFeedSources(ctx)
+	if err != nil {
+		return err
+	}
+	for _, src := range stored {
+		if _, has := cfg.RSSSources[src.Name]; has {
+			continue
+		}
+		feeds = append(feeds, opml.Feed{
+			Title:    src.Name,
+			FeedURL:  src.FeedURL,
+			Category: src.Category,
+		})
+	}
+
+	slices.SortFunc(feeds, func(a, b opml.Feed) int {
+		return strings.Compare(a.Title, b.Title)
+	})
+
+	return opml.New("InsightCourier sources", feeds).Write(w)
+}
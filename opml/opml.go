@@ -0,0 +1,144 @@
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a subscription outline flattened from the OPML tree.
+type Feed struct {
+	Title   string
+	FeedURL string
+	SiteURL string
+	// Category is a "/" separated path of parent outlines.
+	Category string
+}
+
+func Parse(r io.Reader) (*OPML, error) {
+	var o OPML
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReaderLabel
+
+	err := d.Decode(&o)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode OPML. %w", err)
+	}
+	return &o, nil
+}
+
+// Feeds returns all outlines with xmlUrl attribute.
+func (o *OPML) Feeds() []Feed {
+	var result []Feed
+	var walk func(outlines []Outline, category []string)
+	walk = func(outlines []Outline, category []string) {
+		for _, ol := range outlines {
+			if ol.XMLURL != "" {
+				title := ol.Title
+				if title == "" {
+					title = ol.Text
+				}
+				result = append(result, Feed{
+					Title:    title,
+					FeedURL:  ol.XMLURL,
+					SiteURL:  ol.HTMLURL,
+					Category: strings.Join(category, "/"),
+				})
+			}
+
+			if len(ol.Outlines) > 0 {
+				name := ol.Text
+				if name == "" {
+					name = ol.Title
+				}
+				walk(ol.Outlines, append(category[:len(category):len(category)], name))
+			}
+		}
+	}
+	walk(o.Body.Outlines, nil)
+
+	return result
+}
+
+// New builds OPML 2.0 document from the feeds, nesting them into outlines by category.
+func New(title string, feeds []Feed) *OPML {
+	o := &OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, f := range feeds {
+		outlines := &o.Body.Outlines
+		if f.Category != "" {
+			for _, c := range strings.Split(f.Category, "/") {
+				outlines = &findOrAddCategory(outlines, c).Outlines
+			}
+		}
+		*outlines = append(*outlines, Outline{
+			Text:    f.Title,
+			Title:   f.Title,
+			Type:    "rss",
+			XMLURL:  f.FeedURL,
+			HTMLURL: f.SiteURL,
+		})
+	}
+
+	return o
+}
+
+func findOrAddCategory(outlines *[]Outline, name string) *Outline {
+	for i := range *outlines {
+		if (*outlines)[i].XMLURL == "" && (*outlines)[i].Text == name {
+			return &(*outlines)[i]
+		}
+	}
+	*outlines = append(*outlines, Outline{Text: name})
+	return &(*outlines)[len(*outlines)-1]
+}
+
+func (o *OPML) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("unable to write OPML header. %w", err)
+	}
+
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(o); err != nil {
+		return fmt.Errorf("unable to encode OPML. %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
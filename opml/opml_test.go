@@ -0,0 +1,73 @@
+package opml
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const subscriptions = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Go blog" xmlUrl="https://go.dev/blog/feed.atom" htmlUrl="https://go.dev/blog"/>
+    <outline text="Tech">
+      <outline title="Databases">
+        <outline text="Postgres" title="PostgreSQL news" type="rss" xmlUrl="https://www.postgresql.org/news.rss"/>
+      </outline>
+      <outline text="Caf&#233;" xmlUrl="https://cafe.example.com/rss"/>
+    </outline>
+    <outline text="Empty folder"/>
+  </body>
+</opml>`
+
+func TestParseFeeds(t *testing.T) {
+	o, err := Parse(strings.NewReader(subscriptions))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Feed{
+		{Title: "Go blog", FeedURL: "https://go.dev/blog/feed.atom", SiteURL: "https://go.dev/blog"},
+		{Title: "PostgreSQL news", FeedURL: "https://www.postgresql.org/news.rss", Category: "Tech/Databases"},
+		{Title: "Café", FeedURL: "https://cafe.example.com/rss", Category: "Tech"},
+	}
+	if got := o.Feeds(); !slices.Equal(got, want) {
+		t.Errorf("Feeds() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("<opml><body>")); err == nil {
+		t.Error("Parse() of truncated document succeeded")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	feeds := []Feed{
+		{Title: "Go blog", FeedURL: "https://go.dev/blog/feed.atom", SiteURL: "https://go.dev/blog"},
+		{Title: "PostgreSQL news", FeedURL: "https://www.postgresql.org/news.rss", Category: "Tech/Databases"},
+		{Title: "Redis", FeedURL: "https://redis.io/blog/feed.xml", Category: "Tech/Databases"},
+		{Title: "Lobsters", FeedURL: "https://lobste.rs/rss", Category: "Tech"},
+	}
+
+	var buf bytes.Buffer
+	if err := New("Export", feeds).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Version != "2.0" || o.Head.Title != "Export" {
+		t.Errorf("head = %s %+v, want version 2.0 titled Export", o.Version, o.Head)
+	}
+	if len(o.Body.Outlines) != 2 {
+		t.Errorf("got %d top outlines, want the feed and the shared category", len(o.Body.Outlines))
+	}
+	if got := o.Feeds(); !slices.Equal(got, feeds) {
+		t.Errorf("Feeds() = %+v, want %+v", got, feeds)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/config"
+	"github.com/pavelpuchok/insightcourier/feed"
+	"github.com/pavelpuchok/insightcourier/storage"
+	"github.com/pavelpuchok/insightcourier/storage/psql"
+)
+
+type Reporter interface {
+	Report(context.Context, feed.Item, int32) (int, error)
+	Edit(context.Context, storage.Message) error
+}
+
+// Dispatcher sends messages from the outbox. Messages are sent at least once: a message
+// is sent again only if the process fails before the sent message ID is recorded.
+// Failed messages are retried with exponential backoff and become dead after Retry.MaxAttempts attempts.
+type Dispatcher struct {
+	Storage  Storage
+	Reporter Reporter
+	Config   config.JobQueueConfig
+	Retry    config.RetryConfig
+}
+
+// Run dispatches messages until ctx is done. The message in progress is finished before returning.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		msg, err := d.Storage.ClaimOutboxMessage(ctx, d.Config.VisibilityTimeout)
+		if err == nil {
+			mctx := context.WithoutCancel(ctx)
+			if err := d.dispatch(mctx, msg); errors.Is(err, errNotReported) {
+				d.deferMessage(mctx, msg, err)
+			} else if err != nil {
+				d.fail(mctx, msg, err)
+			}
+			continue
+		}
+		if !errors.Is(err, storage.ErrOutboxMessageNotFound) {
+			slog.Error("Failed to claim outbox message", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.Config.PollInterval):
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, msg storage.OutboxMessage) error {
+	switch msg.Kind {
+	case psql.OutboxKindReport, psql.OutboxKindEdit:
+		return d.dispatchItem(ctx, msg)
+	case psql.OutboxKindCoverage:
+		return d.dispatchCoverage(ctx, msg)
+	default:
+		return fmt.Errorf("unsupported outbox message kind %s", msg.Kind)
+	}
+}
+
+// dispatchItem reports the feed item or edits its reported message and marks the item as reported.
+func (d *Dispatcher) dispatchItem(ctx context.Context, msg storage.OutboxMessage) error {
+	seen, err := d.Storage.GetSeenItem(ctx, msg.SourceName, msg.ItemKey)
+	if err != nil {
+		return fmt.Errorf("failed to read feed item (%s). %w", msg.ItemKey, err)
+	}
+	it := seen.Item
+
+	messageID := msg.MessageID
+	switch {
+	case msg.Kind == psql.OutboxKindEdit:
+		// the updated version takes over the message, so it's rendered as updated
+		if err := d.Storage.SetSourceItemMessageID(ctx, msg.SourceItemID, messageID); err != nil {
+			return fmt.Errorf("failed to save edited message. Link: %s. %w", it.Link, err)
+		}
+		if err := d.edit(ctx, messageID); err != nil {
+			return fmt.Errorf("failed to edit reported feed item. Link: %s. %w", it.Link, err)
+		}
+	case messageID == 0:
+		messageID, err = d.Reporter.Report(ctx, it, msg.SourceItemID)
+		if err != nil {
+			return fmt.Errorf("failed to report feed item. Link: %s. %w", it.Link, err)
+		}
+		if err := d.Storage.SetOutboxMessageID(ctx, msg, messageID); err != nil {
+			return err
+		}
+	}
+
+	return inTx(ctx, d.Storage, func(ctx context.Context) error {
+		if err := d.Storage.SetSourceItemMessageID(ctx, msg.SourceItemID, messageID); err != nil {
+			return fmt.Errorf("failed to save reported message. Link: %s. %w", it.Link, err)
+		}
+
+		// the item could be updated by the publisher while the message was sent
+		seen, err := d.Storage.GetSeenItem(ctx, msg.SourceName, msg.ItemKey)
+		if err != nil {
+			return fmt.Errorf("failed to read feed item (%s). %w", msg.ItemKey, err)
+		}
+		seen.MessageID = messageID
+		if seen.SourceItemID == msg.SourceItemID {
+			seen.Status = psql.SeenItemsStatusReported
+			seen.LastError = ""
+		}
+		if err := d.Storage.SaveSeenItem(ctx, msg.SourceName, *seen); err != nil {
+			return fmt.Errorf("failed to save seen feed item. Link: %s. %w", it.Link, err)
+		}
+
+		return d.Storage.CompleteOutboxMessage(ctx, msg)
+	})
+}
+
+// errNotReported is returned by coverage updates of items whose report isn't sent yet,
+// so the update is deferred until the report is sent.
+var errNotReported = errors.New("source item isn't reported yet")
+
+// dispatchCoverage updates the reported message with number of sources which published the item.
+func (d *Dispatcher) dispatchCoverage(ctx context.Context, msg storage.OutboxMessage) error {
+	c, err := d.Storage.GetSourceItemCoverage(ctx, msg.SourceItemID)
+	if err != nil {
+		return err
+	}
+	if c.MessageID == 0 {
+		return fmt.Errorf("failed to update coverage. Link: %s. %w", c.URL, errNotReported)
+	}
+
+	if c.OtherSources != 0 {
+		if err := d.edit(ctx, c.MessageID); err != nil {
+			return fmt.Errorf("failed to update coverage of reported feed item. Link: %s. %w", c.URL, err)
+		}
+	}
+
+	return d.Storage.CompleteOutboxMessage(ctx, msg)
+}
+
+// edit renders the message from its stored state, which includes all changes made so far.
+func (d *Dispatcher) edit(ctx context.Context, messageID int) error {
+	m, err := d.Storage.GetMessage(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	return d.Reporter.Edit(ctx, m)
+}
+
+// deferMessage puts the message back to the outbox without counting the attempt, so waiting
+// for another message doesn't make it dead.
+func (d *Dispatcher) deferMessage(ctx context.Context, msg storage.OutboxMessage, reason error) {
+	runAt := time.Now().Add(d.Retry.BaseDelay)
+	if err := d.Storage.DeferOutboxMessage(ctx, msg, reason.Error(), runAt); err != nil {
+		slog.Error("Failed to defer outbox message", slog.Int64("outbox.id", msg.ID), slog.String("error", err.Error()))
+		return
+	}
+	slog.Debug("Outbox message deferred",
+		slog.Int64("outbox.id", msg.ID),
+		slog.String("outbox.kind", string(msg.Kind)),
+		slog.Time("nextAttemptAt", runAt),
+		slog.String("reason", reason.Error()),
+	)
+}
+
+// fail schedules the next attempt to send the message, or moves it to the dead state after
+// Retry.MaxAttempts attempts, and records the failure in the item state.
+func (d *Dispatcher) fail(ctx context.Context, msg storage.OutboxMessage, cause error) {
+	var runAt time.Time
+	if msg.Attempts >= d.Retry.MaxAttempts {
+		if err := d.Storage.BuryOutboxMessage(ctx, msg, cause.Error()); err != nil {
+			slog.Error("Failed to move outbox message to dead state", slog.Int64("outbox.id", msg.ID), slog.String("error", err.Error()))
+		}
+	} else {
+		runAt = time.Now().Add(retryDelay(d.Retry, msg.Attempts))
+		if err := d.Storage.RetryOutboxMessage(ctx, msg, cause.Error(), runAt); err != nil {
+			slog.Error("Failed to schedule outbox message retry", slog.Int64("outbox.id", msg.ID), slog.String("error", err.Error()))
+		}
+	}
+
+	slog.Warn("Failed outbox message dispatching",
+		slog.Int64("outbox.id", msg.ID),
+		slog.String("outbox.kind", string(msg.Kind)),
+		slog.String("source.name", msg.SourceName),
+		slog.String("item.key", msg.ItemKey),
+		slog.Int("attempts", msg.Attempts),
+		slog.Time("nextAttemptAt", runAt),
+		slog.String("error", cause.Error()),
+	)
+
+	if msg.ItemKey == "" {
+		return
+	}
+
+	seen, err := d.Storage.GetSeenItem(ctx, msg.SourceName, msg.ItemKey)
+	if err != nil {
+		slog.Error("Failed to read failed feed item", slog.String("item.key", msg.ItemKey), slog.String("error", err.Error()))
+		return
+	}
+	if seen.SourceItemID != msg.SourceItemID {
+		return
+	}
+
+	seen.Status = psql.SeenItemsStatusFailed
+	seen.Attempts = msg.Attempts
+	seen.LastError = cause.Error()
+	if err := d.Storage.SaveSeenItem(ctx, msg.SourceName, *seen); err != nil {
+		slog.Error("Failed to save failed feed item", slog.String("item.key", msg.ItemKey), slog.String("error", err.Error()))
+	}
+}
@@ -2,17 +2,39 @@ package planner
 
 import (
 	"context"
+	"log/slog"
+	"math/rand/v2"
 	"time"
+
+	"github.com/robfig/cron/v3"
 )
 
+// Store persists planned run times, so restarts don't change the schedule.
+type Store interface {
+	GetSourceNextRunAt(ctx context.Context, source string) (time.Time, error)
+	SetSourceNextRunAt(ctx context.Context, source string, t time.Time) error
+}
+
+// InMemoryPlanner runs actions on their schedules. Actions continue their persisted schedule after
+// restart. Overdue or never planned actions with interval schedules run at startup, spread randomly
+// over Stagger, and ones with cron schedules run at their next activation.
+// Random delay up to Jitter is added to every run, so many actions scheduled at the same time
+// don't run at the same instant.
 type InMemoryPlanner struct {
+	Jitter  time.Duration
+	Stagger time.Duration
+	// Store is optional, actions run at startup if it isn't set.
+	Store Store
 }
 
-func (p *InMemoryPlanner) AddJob(ctx context.Context, interval time.Duration, action func()) {
-	go func() {
-		t := time.NewTicker(interval)
+// AddJob runs action named name on the schedule until ctx is done.
+func (p *InMemoryPlanner) AddJob(ctx context.Context, name string, schedule Schedule, action func()) {
+	// planned is the run time on the schedule, the action runs at planned time with jitter
+	planned := p.restore(ctx, name, schedule)
 
-		action()
+	go func() {
+		t := time.NewTimer(time.Until(planned))
+		defer t.Stop()
 
 		for {
 			select {
@@ -21,6 +43,73 @@ func (p *InMemoryPlanner) AddJob(ctx context.Context, interval time.Duration, ac
 			case <-t.C:
 				action()
 			}
+
+			// the schedule is followed from planned rather than actual run times, so jitter doesn't accumulate,
+			// unless the action took so long that the planned run is already missed
+			now := time.Now()
+			next := schedule.Next(planned)
+			if next.Before(now) {
+				next = schedule.Next(now)
+			}
+			if next.IsZero() {
+				return
+			}
+			planned = next
+			runAt := planned.Add(randDuration(p.Jitter))
+			p.persist(ctx, name, runAt)
+			t.Reset(time.Until(runAt))
 		}
 	}()
 }
+
+// restore returns the persisted next run time of the action if it's still ahead. The persisted time
+// is ignored if it's beyond the schedule, which happens when the schedule is changed to a more frequent
+// one. Otherwise interval schedules run soon, spread over Stagger, and cron schedules run at their
+// next activation.
+func (p *InMemoryPlanner) restore(ctx context.Context, name string, schedule Schedule) time.Time {
+	now := time.Now()
+	if p.Store != nil {
+		next, err := p.Store.GetSourceNextRunAt(ctx, name)
+		if err != nil {
+			slog.Error("Failed to restore planned run", slog.String("source.name", name), slog.String("error", err.Error()))
+		} else if next.After(now) {
+			if planned := schedule.Next(now); !planned.IsZero() && next.After(planned.Add(p.Jitter)) {
+				next = planned.Add(p.Jitter)
+			}
+			return next
+		}
+	}
+
+	next := now.Add(randDuration(p.Stagger))
+	if planned := schedule.Next(now); !isInterval(schedule) && !planned.IsZero() {
+		next = planned.Add(randDuration(p.Jitter))
+	}
+	p.persist(ctx, name, next)
+	return next
+}
+
+// isInterval reports whether the schedule runs at fixed intervals rather than at set times.
+func isInterval(schedule Schedule) bool {
+	switch schedule.(type) {
+	case every, *AdaptiveSchedule, cron.ConstantDelaySchedule:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *InMemoryPlanner) persist(ctx context.Context, name string, next time.Time) {
+	if p.Store == nil {
+		return
+	}
+	if err := p.Store.SetSourceNextRunAt(ctx, name, next); err != nil {
+		slog.Error("Failed to save planned run", slog.String("source.name", name), slog.String("error", err.Error()))
+	}
+}
+
+func randDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d)
+}
@@ -0,0 +1,59 @@
+package planner
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule returns the next activation time after the given one, or zero time if there is none.
+type Schedule interface {
+	Next(time.Time) time.Time
+}
+
+// Parse parses standard 5-field cron spec, e.g. "*/30 8-20 * * 1-5" for every 30 minutes on weekdays
+// from 8 to 20, or descriptors like "@hourly" and "@every 15m". Time zone can be set with
+// "CRON_TZ=Europe/Berlin" prefix.
+func Parse(spec string) (Schedule, error) {
+	s, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q. %w", spec, err)
+	}
+	return s, nil
+}
+
+// Every returns schedule activated every interval.
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// AdaptiveSchedule is activated every interval which can be changed while the schedule is in use.
+type AdaptiveSchedule struct {
+	interval atomic.Int64
+}
+
+func NewAdaptive(interval time.Duration) *AdaptiveSchedule {
+	s := &AdaptiveSchedule{}
+	s.SetInterval(interval)
+	return s
+}
+
+func (s *AdaptiveSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Interval())
+}
+
+func (s *AdaptiveSchedule) Interval() time.Duration {
+	return time.Duration(s.interval.Load())
+}
+
+func (s *AdaptiveSchedule) SetInterval(interval time.Duration) {
+	s.interval.Store(int64(interval))
+}
@@ -0,0 +1,63 @@
+package planner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database is unavailable")
+	}
+
+	// Friday
+	now := time.Date(2026, 10, 16, 20, 10, 0, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "*/30 8-20 * * 1-5", want: time.Date(2026, 10, 16, 20, 30, 0, 0, time.UTC)},
+		{spec: "0 8-20 * * 1-5", want: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)},
+		{spec: "@every 15m", want: now.Add(15 * time.Minute)},
+		{spec: "CRON_TZ=Europe/Berlin 0 9 * * *", want: time.Date(2026, 10, 17, 9, 0, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(now); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * *", "0 0 * * * *", "61 * * * *", "@weekdays", "@every soon", "CRON_TZ=Nowhere/Land * * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestAdaptiveSchedule(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	s := NewAdaptive(time.Hour)
+	if got := s.Next(now); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("Next() = %s, want an hour later", got)
+	}
+
+	s.SetInterval(10 * time.Minute)
+	if got := s.Next(now); !got.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("Next() = %s, want the changed interval later", got)
+	}
+	if got := Every(time.Minute).Next(now); !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("Every().Next() = %s, want a minute later", got)
+	}
+}
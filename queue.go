@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/config"
+	"github.com/pavelpuchok/insightcourier/storage"
+)
+
+type JobStorage interface {
+	EnqueueJob(ctx context.Context, job storage.Job, runAt time.Time) (bool, error)
+	ClaimJob(ctx context.Context, visibilityTimeout time.Duration) (storage.Job, error)
+	DeleteJob(ctx context.Context, job storage.Job) error
+	RetryJob(ctx context.Context, job storage.Job, lastError string, runAt time.Time) error
+	DeferJob(ctx context.Context, job storage.Job, reason string, runAt time.Time) error
+	BuryJob(ctx context.Context, job storage.Job, lastError string) error
+}
+
+// JobQueue is a durable job queue shared by workers of all instances using the same storage.
+// It keeps at most one queued job of a kind per source or item, coalescing duplicates, and hands
+// out at most one in-flight job of a kind, so the same work never runs concurrently.
+// Failed jobs are retried with exponential backoff and become dead after Retry.MaxAttempts attempts.
+type JobQueue struct {
+	Storage JobStorage
+	Config  config.JobQueueConfig
+	Retry   config.RetryConfig
+}
+
+// Push enqueues the job. Returns false if the same job is already queued.
+func (q *JobQueue) Push(ctx context.Context, job storage.Job) (bool, error) {
+	job.MaxAttempts = q.Retry.MaxAttempts
+	return q.Storage.EnqueueJob(ctx, job, time.Now())
+}
+
+// Pop blocks until there is a due job or context is done. Either Done or Fail must be called
+// once the job is processed, otherwise the job is handed out again after the visibility timeout.
+func (q *JobQueue) Pop(ctx context.Context) (storage.Job, error) {
+	for {
+		job, err := q.Storage.ClaimJob(ctx, q.Config.VisibilityTimeout)
+		if err == nil {
+			return job, nil
+		}
+		if ctx.Err() != nil {
+			return storage.Job{}, ctx.Err()
+		}
+		if !errors.Is(err, storage.ErrJobNotFound) {
+			slog.Error("Failed to claim job", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return storage.Job{}, ctx.Err()
+		case <-time.After(q.Config.PollInterval):
+		}
+	}
+}
+
+// Done removes the processed job from the queue.
+func (q *JobQueue) Done(ctx context.Context, job storage.Job) error {
+	return q.Storage.DeleteJob(ctx, job)
+}
+
+// Fail schedules the next attempt of the failed job. Returns zero time if the job is dead.
+func (q *JobQueue) Fail(ctx context.Context, job storage.Job, cause error) (time.Time, error) {
+	if job.Attempts >= job.MaxAttempts {
+		if err := q.Storage.BuryJob(ctx, job, cause.Error()); err != nil {
+			return time.Time{}, fmt.Errorf("failed to move job to dead state. %w", err)
+		}
+		return time.Time{}, nil
+	}
+
+	runAt := time.Now().Add(retryDelay(q.Retry, job.Attempts))
+	if err := q.Storage.RetryJob(ctx, job, cause.Error(), runAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to schedule job retry. %w", err)
+	}
+	return runAt, nil
+}
+
+// Defer puts the job back to the queue to run at runAt. Unlike Fail, the attempt isn't counted,
+// so a job waiting for an unavailable dependency doesn't become dead.
+func (q *JobQueue) Defer(ctx context.Context, job storage.Job, reason error, runAt time.Time) error {
+	if err := q.Storage.DeferJob(ctx, job, reason.Error(), runAt); err != nil {
+		return fmt.Errorf("failed to defer job. %w", err)
+	}
+	return nil
+}
+
+// retryDelay returns delay before the next attempt after the given number of failed attempts.
+func retryDelay(retry config.RetryConfig, attempts int) time.Duration {
+	d := retry.BaseDelay
+	for i := 1; i < attempts && d < retry.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, retry.MaxDelay)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavelpuchok/insightcourier/storage"
+)
+
+// runSchedule implements "schedule" subcommand which prints upcoming source fetches.
+func runSchedule(ctx context.Context, s *storage.PostgreSQL, args []string) error {
+	fset := flag.NewFlagSet("schedule", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: insightcourier schedule")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	runs, err := s.ListSourceRuns(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tNEXT RUN\tIN\tLAST FETCHED AT")
+	for _, r := range runs {
+		lastFetchedAt := "-"
+		if !r.LastFetchedAt.IsZero() {
+			lastFetchedAt = r.LastFetchedAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.NextRunAt.Local().Format(time.DateTime), r.NextRunAt.Sub(now).Round(time.Second), lastFetchedAt)
+	}
+	return w.Flush()
+}
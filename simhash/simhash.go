@@ -0,0 +1,68 @@
+package simhash
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleSize is number of consecutive words hashed together as a single feature.
+const shingleSize = 3
+
+// MinWords is the minimal text length for which fingerprint is meaningful.
+const MinWords = 50
+
+// Fingerprint computes 64 bit SimHash of the text using word shingles as features.
+// Texts differing in a few words produce fingerprints differing in a few bits.
+func Fingerprint(text string) uint64 {
+	words := Words(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	n := max(len(words)-shingleSize+1, 1)
+	for i := range n {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+shingleSize, len(words))], " ")))
+		sum := h.Sum64()
+
+		for b := range 64 {
+			if sum&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var fp uint64
+	for b, w := range weights {
+		if w > 0 {
+			fp |= 1 << b
+		}
+	}
+	return fp
+}
+
+// Distance returns Hamming distance between fingerprints.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Bands is number of 16 bit bands the fingerprint is split into. Fingerprints within distance
+// of Bands-1 have at least one equal band, so near duplicates can be looked up by bands.
+const Bands = 4
+
+// Band returns i-th 16 bit band of the fingerprint.
+func Band(fp uint64, i int) uint16 {
+	return uint16(fp >> (16 * i))
+}
+
+// Words splits text into lowercased words ignoring punctuation.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,95 @@
+package simhash
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{a: 0, b: 0, want: 0},
+		{a: 0xdeadbeef, b: 0xdeadbeef, want: 0},
+		{a: 0, b: 1, want: 1},
+		{a: 0b1010, b: 0b0101, want: 4},
+		{a: 1 << 63, b: 1, want: 2},
+		{a: 0, b: ^uint64(0), want: 64},
+	}
+
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%#x, %#x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Distance(tt.b, tt.a); got != tt.want {
+			t.Errorf("Distance(%#x, %#x) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestBand(t *testing.T) {
+	const fp = 0x1111_2222_3333_4444
+	tests := []struct {
+		i    int
+		want uint16
+	}{
+		{i: 0, want: 0x4444},
+		{i: 1, want: 0x3333},
+		{i: 2, want: 0x2222},
+		{i: 3, want: 0x1111},
+	}
+
+	for _, tt := range tests {
+		if got := Band(fp, tt.i); got != tt.want {
+			t.Errorf("Band(%#x, %d) = %#x, want %#x", uint64(fp), tt.i, got, tt.want)
+		}
+	}
+}
+
+// TestBandsShared checks that the band lookup finds every fingerprint the full scan would find
+// with distance below Bands.
+func TestBandsShared(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 10000 {
+		a := r.Uint64()
+		b := a
+		for _, bit := range r.Perm(64)[:r.IntN(Bands)] {
+			b ^= 1 << bit
+		}
+
+		shared := false
+		for i := range Bands {
+			if Band(a, i) == Band(b, i) {
+				shared = true
+			}
+		}
+		if !shared {
+			t.Fatalf("fingerprints %#x and %#x within distance %d share no band", a, b, Distance(a, b))
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	words := strings.Fields(strings.Repeat("the quick brown fox jumps over the lazy dog while a cat watches ", 8))
+	text := strings.Join(words, " ")
+
+	edited := slices.Clone(words)
+	edited[40] = "wolf"
+	other := strings.Repeat("completely unrelated article about database migrations and query planning ", 8)
+
+	if Fingerprint(text) != Fingerprint(strings.ToUpper(text)+"!") {
+		t.Error("fingerprint depends on case or punctuation")
+	}
+	if d := Distance(Fingerprint(text), Fingerprint(strings.Join(edited, " "))); d > 8 {
+		t.Errorf("distance of texts differing in a word = %d, want small", d)
+	}
+	if d := Distance(Fingerprint(text), Fingerprint(other)); d < 16 {
+		t.Errorf("distance of unrelated texts = %d, want large", d)
+	}
+	if Fingerprint("") != 0 {
+		t.Error("fingerprint of empty text isn't zero")
+	}
+}
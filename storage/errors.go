@@ -3,6 +3,11 @@ package storage
 import "errors"
 
 var (
-	ErrSourceAlreadyExists = errors.New("source already exists")
-	ErrSourceNotFound      = errors.New("source not found")
+	ErrSourceAlreadyExists     = errors.New("source already exists")
+	ErrSourceNotFound          = errors.New("source not found")
+	ErrSeenItemNotFound        = errors.New("seen item not found")
+	ErrSourceItemNotFound      = errors.New("source item not found")
+	ErrSourceItemAlreadyExists = errors.New("source item with the canonical URL already exists")
+	ErrJobNotFound             = errors.New("job not found")
+	ErrOutboxMessageNotFound   = errors.New("outbox message not found")
 )
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -10,17 +11,28 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pavelpuchok/insightcourier/config"
+	"github.com/pavelpuchok/insightcourier/feed"
+	"github.com/pavelpuchok/insightcourier/simhash"
 	"github.com/pavelpuchok/insightcourier/storage/psql"
 )
 
 type PostgreSQL struct {
-	conn    *pgx.Conn
+	conn    *pgxpool.Pool
 	timeout time.Duration
 }
 
 func NewPostgreSQL(ctx context.Context, config config.PSQLStorageConfig) (*PostgreSQL, error) {
-	conn, err := pgx.Connect(ctx, config.ConnString)
+	poolConfig, err := pgxpool.ParseConfig(config.ConnString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL connection string. %w", err)
+	}
+	if config.MaxConns > 0 {
+		poolConfig.MaxConns = config.MaxConns
+	}
+
+	conn, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to PostgreSQL DB. %w", err)
 	}
@@ -82,6 +94,71 @@ func (pq *PostgreSQL) CreateSource(ctx context.Context, source string) (int32, e
 	return id, nil
 }
 
+// FeedSource is an RSS source defined in storage rather than in the config file.
+type FeedSource struct {
+	Name           string
+	FeedURL        string
+	UpdateInterval time.Duration
+	Category       string
+}
+
+func (pq *PostgreSQL) CreateFeedSource(ctx context.Context, source FeedSource) (int32, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	id, err := q.CreateFeedSource(cctx, psql.CreateFeedSourceParams{
+		Name:           source.Name,
+		FeedUrl:        pgtype.Text{String: source.FeedURL, Valid: true},
+		UpdateInterval: pgtype.Interval{Microseconds: source.UpdateInterval.Microseconds(), Valid: source.UpdateInterval > 0},
+		Category:       pgtype.Text{String: source.Category, Valid: source.Category != ""},
+	})
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return 0, ErrSourceAlreadyExists
+			}
+		}
+		return 0, fmt.Errorf("failed to create feed source (%s). %w", source.Name, err)
+	}
+
+	return id, nil
+}
+
+func (pq *PostgreSQL) ListFeedSources(ctx context.Context) ([]FeedSource, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	rows, err := q.ListFeedSources(cctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feed sources. %w", err)
+	}
+
+	result := make([]FeedSource, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, FeedSource{
+			Name:           r.Name,
+			FeedURL:        r.FeedUrl.String,
+			UpdateInterval: intervalDuration(r.UpdateInterval),
+			Category:       r.Category.String,
+		})
+	}
+
+	return result, nil
+}
+
+// intervalDuration converts PostgreSQL interval to duration, assuming 24 hours days and 30 days months.
+func intervalDuration(i pgtype.Interval) time.Duration {
+	if !i.Valid {
+		return 0
+	}
+	days := time.Duration(i.Days) + time.Duration(i.Months)*30
+	return time.Duration(i.Microseconds)*time.Microsecond + days*24*time.Hour
+}
+
 func (pq *PostgreSQL) getQueriesFromContext(ctx context.Context) *psql.Queries {
 	q := psql.New(pq.conn)
 	tx, ok := ctx.Value(postgreSQLTxKey).(pgx.Tx)
@@ -121,16 +198,227 @@ func (pq *PostgreSQL) SetSourceUpdateTime(ctx context.Context, source string, t
 	return nil
 }
 
+// DiscoveredFeed is the feed found on the site of a source.
+type DiscoveredFeed struct {
+	SiteURL string
+	FeedURL string
+}
+
+func (pq *PostgreSQL) GetDiscoveredFeed(ctx context.Context, source string) (DiscoveredFeed, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	r, err := q.GetSourceDiscoveredFeedByName(cctx, source)
+	if err != nil {
+		return DiscoveredFeed{}, fmt.Errorf("failed to get source (%s) discovered feed. %w", source, err)
+	}
+
+	return DiscoveredFeed{SiteURL: r.SiteUrl.String, FeedURL: r.DiscoveredFeedUrl.String}, nil
+}
+
+func (pq *PostgreSQL) SetDiscoveredFeed(ctx context.Context, source string, f DiscoveredFeed) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.SetSourceDiscoveredFeedByName(cctx, psql.SetSourceDiscoveredFeedByNameParams{
+		Name:              source,
+		SiteUrl:           pgtype.Text{String: f.SiteURL, Valid: f.SiteURL != ""},
+		DiscoveredFeedUrl: pgtype.Text{String: f.FeedURL, Valid: f.FeedURL != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set source (%s) discovered feed. %w", source, err)
+	}
+
+	return nil
+}
+
+// HTTPCache holds conditional request validators of a source and the time before which
+// it should not be requested again.
+type HTTPCache struct {
+	ETag         string
+	LastModified string
+	NotBefore    time.Time
+}
+
+func (pq *PostgreSQL) GetHTTPCache(ctx context.Context, source string) (HTTPCache, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	r, err := q.GetSourceHTTPCacheByName(cctx, source)
+	if err != nil {
+		return HTTPCache{}, fmt.Errorf("failed to get source (%s) HTTP cache. %w", source, err)
+	}
+
+	return HTTPCache{
+		ETag:         r.HttpEtag.String,
+		LastModified: r.HttpLastModified.String,
+		NotBefore:    r.FetchNotBefore.Time,
+	}, nil
+}
+
+func (pq *PostgreSQL) SetHTTPCache(ctx context.Context, source string, c HTTPCache) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.SetSourceHTTPCacheByName(cctx, psql.SetSourceHTTPCacheByNameParams{
+		Name:             source,
+		HttpEtag:         pgtype.Text{String: c.ETag, Valid: c.ETag != ""},
+		HttpLastModified: pgtype.Text{String: c.LastModified, Valid: c.LastModified != ""},
+		FetchNotBefore:   pgtype.Timestamptz{Time: c.NotBefore, Valid: !c.NotBefore.IsZero()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set source (%s) HTTP cache. %w", source, err)
+	}
+
+	return nil
+}
+
+// GetSourcePollInterval returns the persisted adaptive polling interval of the source, zero if it isn't set.
+func (pq *PostgreSQL) GetSourcePollInterval(ctx context.Context, source string) (time.Duration, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	i, err := q.GetSourcePollIntervalByName(cctx, source)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrSourceNotFound
+		}
+		return 0, fmt.Errorf("failed to get source (%s) poll interval. %w", source, err)
+	}
+
+	return intervalDuration(i), nil
+}
+
+func (pq *PostgreSQL) SetSourcePollInterval(ctx context.Context, source string, d time.Duration) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.SetSourcePollIntervalByName(cctx, psql.SetSourcePollIntervalByNameParams{
+		Name:         source,
+		PollInterval: pgtype.Interval{Microseconds: d.Microseconds(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set source (%s) poll interval. %w", source, err)
+	}
+
+	return nil
+}
+
+// GetSourceNextRunAt returns the planned time of the next source fetch, zero if it isn't planned yet.
+func (pq *PostgreSQL) GetSourceNextRunAt(ctx context.Context, source string) (time.Time, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	t, err := q.GetSourceNextRunAtByName(cctx, source)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrSourceNotFound
+		}
+		return time.Time{}, fmt.Errorf("failed to get source (%s) next run time. %w", source, err)
+	}
+
+	return t.Time, nil
+}
+
+func (pq *PostgreSQL) SetSourceNextRunAt(ctx context.Context, source string, t time.Time) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.SetSourceNextRunAtByName(cctx, psql.SetSourceNextRunAtByNameParams{
+		Name:      source,
+		NextRunAt: pgtype.Timestamptz{Time: t, Valid: !t.IsZero()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set source (%s) next run time. %w", source, err)
+	}
+
+	return nil
+}
+
+// SourceRun is a planned source fetch.
+type SourceRun struct {
+	Name          string
+	NextRunAt     time.Time
+	LastFetchedAt time.Time
+}
+
+// ListSourceRuns returns planned source fetches ordered by time.
+func (pq *PostgreSQL) ListSourceRuns(ctx context.Context) ([]SourceRun, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	rows, err := q.ListSourcesNextRunAt(cctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source runs. %w", err)
+	}
+
+	result := make([]SourceRun, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, SourceRun{
+			Name:          r.Name,
+			NextRunAt:     r.NextRunAt.Time,
+			LastFetchedAt: r.LastFetchedAt.Time,
+		})
+	}
+
+	return result, nil
+}
+
+// SourceActivity describes items published by the source in a period.
+type SourceActivity struct {
+	Items int
+	First time.Time
+	Last  time.Time
+}
+
+// GetSourceActivity returns activity of the source based on items published after since.
+func (pq *PostgreSQL) GetSourceActivity(ctx context.Context, source string, since time.Time) (SourceActivity, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	r, err := q.GetSourceItemsActivity(cctx, psql.GetSourceItemsActivityParams{
+		Name:        source,
+		PublishedAt: pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return SourceActivity{}, fmt.Errorf("failed to get source (%s) activity. %w", source, err)
+	}
+
+	return SourceActivity{
+		Items: int(r.Items),
+		First: r.FirstPublishedAt.Time,
+		Last:  r.LastPublishedAt.Time,
+	}, nil
+}
+
 type AddSourceItemData struct {
-	SourceName  string
-	URL         string
-	Title       string
-	TextContent string
-	Excerpt     string
-	Language    string
-	PublishedAt time.Time
+	SourceName   string
+	URL          string
+	CanonicalURL string
+	Title        string
+	TextContent  string
+	Excerpt      string
+	Language     string
+	PublishedAt  time.Time
+	// Fingerprint is SimHash of TextContent, zero if the text is too short to compare.
+	Fingerprint uint64
+	// Updated is set for versions of the item extracted again after the publisher updated it.
+	// Only the first version is unique by CanonicalURL.
+	Updated bool
 }
 
+// AddSourceItem saves the source item. Returns ErrSourceItemAlreadyExists if the first version of
+// an item with the same canonical URL is already saved.
 func (pq *PostgreSQL) AddSourceItem(ctx context.Context, item AddSourceItemData) (int32, error) {
 	q := pq.getQueriesFromContext(ctx)
 	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
@@ -141,19 +429,831 @@ func (pq *PostgreSQL) AddSourceItem(ctx context.Context, item AddSourceItemData)
 		return 0, fmt.Errorf("failed to get source ID. %w", err)
 	}
 	sid, err := q.CreateSourceItem(cctx, psql.CreateSourceItemParams{
-		SourceID:    pgtype.Int4{Int32: source_id, Valid: true},
-		Url:         pgtype.Text{String: item.URL, Valid: true},
-		Title:       pgtype.Text{String: item.Title, Valid: true},
-		TextContent: pgtype.Text{String: item.TextContent, Valid: true},
-		Excerpt:     pgtype.Text{String: item.Excerpt, Valid: true},
-		Language:    pgtype.Text{String: item.Language, Valid: true},
-		PublishedAt: pgtype.Timestamptz{Time: item.PublishedAt, Valid: true},
+		SourceID:     pgtype.Int4{Int32: source_id, Valid: true},
+		Url:          pgtype.Text{String: item.URL, Valid: true},
+		CanonicalUrl: pgtype.Text{String: item.CanonicalURL, Valid: item.CanonicalURL != ""},
+		Title:        pgtype.Text{String: item.Title, Valid: true},
+		TextContent:  pgtype.Text{String: item.TextContent, Valid: true},
+		Excerpt:      pgtype.Text{String: item.Excerpt, Valid: true},
+		Language:     pgtype.Text{String: item.Language, Valid: true},
+		PublishedAt:  pgtype.Timestamptz{Time: item.PublishedAt, Valid: !item.PublishedAt.IsZero()},
+		Fingerprint:  pgtype.Int8{Int64: int64(item.Fingerprint), Valid: item.Fingerprint != 0},
+		Updated:      item.Updated,
 	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrSourceItemAlreadyExists
+		}
 		return 0, fmt.Errorf("failed to create source item. %w", err)
 	}
 	return sid, nil
 }
+
+func (pq *PostgreSQL) GetSourceItemIDByCanonicalURL(ctx context.Context, canonicalURL string) (int32, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	sid, err := q.GetSourceItemIDByCanonicalURL(cctx, pgtype.Text{String: canonicalURL, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrSourceItemNotFound
+		}
+		return 0, fmt.Errorf("failed to get source item by canonical URL (%s). %w", canonicalURL, err)
+	}
+
+	return sid, nil
+}
+
+type Fingerprint struct {
+	SourceItemID int32
+	Value        uint64
+}
+
+// ListRecentFingerprints returns fingerprints of source items created after since by other sources.
+func (pq *PostgreSQL) ListRecentFingerprints(ctx context.Context, source string, since time.Time) ([]Fingerprint, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	rows, err := q.ListRecentSourceItemFingerprints(cctx, psql.ListRecentSourceItemFingerprintsParams{
+		CreatedAt: pgtype.Timestamp{Time: since, Valid: true},
+		Name:      source,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent fingerprints. %w", err)
+	}
+
+	result := make([]Fingerprint, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, Fingerprint{
+			SourceItemID: r.SourceItemID,
+			Value:        uint64(r.Fingerprint.Int64),
+		})
+	}
+
+	return result, nil
+}
+
+// ListNearFingerprints returns fingerprints of source items created after since by other sources
+// which have at least one band equal to the fingerprint.
+func (pq *PostgreSQL) ListNearFingerprints(ctx context.Context, source string, since time.Time, fp uint64) ([]Fingerprint, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	band := func(i int) pgtype.Int4 {
+		return pgtype.Int4{Int32: int32(simhash.Band(fp, i)), Valid: true}
+	}
+	rows, err := q.ListNearSourceItemFingerprints(cctx, psql.ListNearSourceItemFingerprintsParams{
+		CreatedAt:        pgtype.Timestamp{Time: since, Valid: true},
+		Name:             source,
+		FingerprintBand0: band(0),
+		FingerprintBand1: band(1),
+		FingerprintBand2: band(2),
+		FingerprintBand3: band(3),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list near fingerprints. %w", err)
+	}
+
+	result := make([]Fingerprint, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, Fingerprint{
+			SourceItemID: r.SourceItemID,
+			Value:        uint64(r.Fingerprint.Int64),
+		})
+	}
+
+	return result, nil
+}
+
+// Coverage describes reported source item and how many sources besides the original one published it.
+type Coverage struct {
+	URL          string
+	MessageID    int
+	OtherSources int
+}
+
+func (pq *PostgreSQL) GetSourceItemCoverage(ctx context.Context, sourceItemID int32) (Coverage, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	r, err := q.GetSourceItemCoverage(cctx, sourceItemID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Coverage{}, ErrSourceItemNotFound
+		}
+		return Coverage{}, fmt.Errorf("failed to get source item (%d) coverage. %w", sourceItemID, err)
+	}
+
+	return Coverage{
+		URL:          r.Url.String,
+		MessageID:    int(r.TelegramMessageID.Int32),
+		OtherSources: int(r.OtherSources),
+	}, nil
+}
+
+// Message is the state of the Telegram message which reported a source item. Versions of the item
+// extracted again after the publisher updated it share the message of the first version.
+type Message struct {
+	ID int
+	// SourceItemID and URL are of the latest version of the item.
+	SourceItemID int32
+	URL          string
+	// Updated is set once the message is edited with an updated version of the item.
+	Updated      bool
+	OtherSources int
+	// Reacted is set once the message got a reaction, its buttons are removed then.
+	Reacted bool
+}
+
+// GetMessage returns the state of the message, ErrSourceItemNotFound if no item is reported by it.
+func (pq *PostgreSQL) GetMessage(ctx context.Context, messageID int) (Message, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	r, err := q.GetTelegramMessage(cctx, pgtype.Int4{Int32: int32(messageID), Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Message{}, ErrSourceItemNotFound
+		}
+		return Message{}, fmt.Errorf("failed to get message (%d). %w", messageID, err)
+	}
+
+	return Message{
+		ID:           messageID,
+		SourceItemID: r.SourceItemID,
+		URL:          r.Url.String,
+		Updated:      r.Updated,
+		OtherSources: int(r.OtherSources),
+		Reacted:      r.Reacted,
+	}, nil
+}
+
+// LinkSourceItem records that the source item was also published by the source.
+func (pq *PostgreSQL) LinkSourceItem(ctx context.Context, source string, sourceItemID int32) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	source_id, err := q.GetSourceIdByName(cctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to get source ID. %w", err)
+	}
+
+	err = q.CreateSourceItemLink(cctx, psql.CreateSourceItemLinkParams{
+		SourceItemID: sourceItemID,
+		SourceID:     source_id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to link source item (%d) to source (%s). %w", sourceItemID, source, err)
+	}
+
+	return nil
+}
+
+func (pq *PostgreSQL) SetSourceItemMessageID(ctx context.Context, sourceItemID int32, messageID int) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.SetSourceItemTelegramMessageID(cctx, psql.SetSourceItemTelegramMessageIDParams{
+		SourceItemID:      sourceItemID,
+		TelegramMessageID: pgtype.Int4{Int32: int32(messageID), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set source item (%d) message ID. %w", sourceItemID, err)
+	}
+
+	return nil
+}
+
+// SeenItem is a feed item discovered in a source along with its processing state.
+type SeenItem struct {
+	Key      string
+	Version  string
+	Status   psql.SeenItemsStatus
+	Attempts int
+	// LastError is the error of the last failed processing attempt.
+	LastError string
+	// SourceItemID is zero until the item content is extracted.
+	SourceItemID int32
+	// MessageID is ID of Telegram message which reported the item, zero if unknown.
+	MessageID int
+	Item      feed.Item
+}
+
+func seenItemFromRow(r psql.GetSeenItemRow) (*SeenItem, error) {
+	si := &SeenItem{
+		Key:          r.ItemKey,
+		Version:      r.ItemVersion,
+		Status:       r.Status,
+		Attempts:     int(r.Attempts),
+		LastError:    r.LastError.String,
+		SourceItemID: r.SourceItemID.Int32,
+		MessageID:    int(r.TelegramMessageID.Int32),
+	}
+
+	if len(r.Item) > 0 {
+		if err := json.Unmarshal(r.Item, &si.Item); err != nil {
+			return nil, fmt.Errorf("failed to decode seen item (%s) data. %w", r.ItemKey, err)
+		}
+	}
+
+	return si, nil
+}
+
+// PruneSeenItems removes reported items of the source seen before the given time. Returns number of removed items.
+func (pq *PostgreSQL) PruneSeenItems(ctx context.Context, source string, before time.Time) (int64, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	n, err := q.DeleteSeenItems(cctx, psql.DeleteSeenItemsParams{
+		Name:      source,
+		CreatedAt: pgtype.Timestamp{Time: before, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune seen items of source %s. %w", source, err)
+	}
+
+	return n, nil
+}
+
+func (pq *PostgreSQL) GetSeenItem(ctx context.Context, source string, key string) (*SeenItem, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	r, err := q.GetSeenItem(cctx, psql.GetSeenItemParams{
+		Name:    source,
+		ItemKey: key,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSeenItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get seen item (%s). %w", key, err)
+	}
+
+	return seenItemFromRow(r)
+}
+
+func (pq *PostgreSQL) SaveSeenItem(ctx context.Context, source string, item SeenItem) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	source_id, err := q.GetSourceIdByName(cctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to get source ID. %w", err)
+	}
+
+	data, err := json.Marshal(item.Item)
+	if err != nil {
+		return fmt.Errorf("failed to encode seen item (%s) data. %w", item.Key, err)
+	}
+
+	err = q.UpsertSeenItem(cctx, psql.UpsertSeenItemParams{
+		SourceID:          source_id,
+		ItemKey:           item.Key,
+		ItemVersion:       item.Version,
+		Status:            item.Status,
+		Attempts:          int32(item.Attempts),
+		LastError:         pgtype.Text{String: item.LastError, Valid: item.LastError != ""},
+		SourceItemID:      pgtype.Int4{Int32: item.SourceItemID, Valid: item.SourceItemID != 0},
+		TelegramMessageID: pgtype.Int4{Int32: int32(item.MessageID), Valid: item.MessageID != 0},
+		Item:              data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save seen item (%s). %w", item.Key, err)
+	}
+
+	return nil
+}
+
+// Job is a unit of work in the job queue. ItemKey is empty for source jobs.
+type Job struct {
+	ID          int64
+	Type        psql.JobsType
+	SourceName  string
+	ItemKey     string
+	Attempts    int
+	MaxAttempts int
+}
+
+// EnqueueJob adds job to the queue to run at runAt. Returns false if the same job is already queued.
+func (pq *PostgreSQL) EnqueueJob(ctx context.Context, job Job, runAt time.Time) (bool, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	source_id, err := q.GetSourceIdByName(cctx, job.SourceName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get source ID. %w", err)
+	}
+
+	_, err = q.EnqueueJob(cctx, psql.EnqueueJobParams{
+		Type:        job.Type,
+		SourceID:    source_id,
+		ItemKey:     job.ItemKey,
+		MaxAttempts: int32(job.MaxAttempts),
+		RunAt:       pgtype.Timestamptz{Time: runAt, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to enqueue %s job of source (%s). %w", job.Type, job.SourceName, err)
+	}
+
+	return true, nil
+}
+
+// ClaimJob locks the next due job for visibilityTimeout. Job which isn't finished in time
+// can be claimed again. Returns ErrJobNotFound if there are no due jobs.
+func (pq *PostgreSQL) ClaimJob(ctx context.Context, visibilityTimeout time.Duration) (Job, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	r, err := q.ClaimJob(cctx, pgtype.Interval{Microseconds: visibilityTimeout.Microseconds(), Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Job{}, ErrJobNotFound
+		}
+		return Job{}, fmt.Errorf("failed to claim job. %w", err)
+	}
+
+	return Job{
+		ID:          r.JobID,
+		Type:        r.Type,
+		SourceName:  r.Name,
+		ItemKey:     r.ItemKey,
+		Attempts:    int(r.Attempts),
+		MaxAttempts: int(r.MaxAttempts),
+	}, nil
+}
+
+// DeleteJob removes the finished job from the queue.
+func (pq *PostgreSQL) DeleteJob(ctx context.Context, job Job) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.DeleteJob(cctx, psql.DeleteJobParams{
+		JobID:    job.ID,
+		Attempts: int32(job.Attempts),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete job (%d). %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// RetryJob puts the failed job back to the queue to run at runAt. If the same job was queued
+// in the meantime, the failed one is removed instead.
+func (pq *PostgreSQL) RetryJob(ctx context.Context, job Job, lastError string, runAt time.Time) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.RetryJob(cctx, psql.RetryJobParams{
+		JobID:     job.ID,
+		Attempts:  int32(job.Attempts),
+		LastError: pgtype.Text{String: lastError, Valid: true},
+		RunAt:     pgtype.Timestamptz{Time: runAt, Valid: true},
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return pq.DeleteJob(ctx, job)
+			}
+		}
+		return fmt.Errorf("failed to retry job (%d). %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// DeferJob puts the job back to the queue to run at runAt without counting its attempt. If the same
+// job was queued in the meantime, the deferred one is removed instead.
+func (pq *PostgreSQL) DeferJob(ctx context.Context, job Job, reason string, runAt time.Time) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.DeferJob(cctx, psql.DeferJobParams{
+		JobID:     job.ID,
+		Attempts:  int32(job.Attempts),
+		LastError: pgtype.Text{String: reason, Valid: true},
+		RunAt:     pgtype.Timestamptz{Time: runAt, Valid: true},
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return pq.DeleteJob(ctx, job)
+			}
+		}
+		return fmt.Errorf("failed to defer job (%d). %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// BuryJob moves the failed job to the dead state, it isn't run anymore.
+func (pq *PostgreSQL) BuryJob(ctx context.Context, job Job, lastError string) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.BuryJob(cctx, psql.BuryJobParams{
+		JobID:     job.ID,
+		Attempts:  int32(job.Attempts),
+		LastError: pgtype.Text{String: lastError, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to bury job (%d). %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// DeadJob is a job which exceeded its attempts.
+type DeadJob struct {
+	Job
+	LastError string
+	FailedAt  time.Time
+	// Link is the feed item link, empty for source jobs.
+	Link string
+}
+
+// ListDeadJobs returns at most limit recently failed dead jobs.
+func (pq *PostgreSQL) ListDeadJobs(ctx context.Context, limit int) ([]DeadJob, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	rows, err := q.ListDeadJobs(cctx, int32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead jobs. %w", err)
+	}
+
+	result := make([]DeadJob, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, DeadJob{
+			Job: Job{
+				ID:         r.JobID,
+				Type:       r.Type,
+				SourceName: r.Name,
+				ItemKey:    r.ItemKey,
+				Attempts:   int(r.Attempts),
+			},
+			LastError: r.LastError.String,
+			FailedAt:  r.UpdatedAt.Time,
+			Link:      r.Link,
+		})
+	}
+
+	return result, nil
+}
+
+// RequeueDeadJob puts the dead job back to the queue with attempts reset. If the same job
+// is already queued, the dead one is removed instead.
+func (pq *PostgreSQL) RequeueDeadJob(ctx context.Context, id int64) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	n, err := q.RequeueDeadJob(cctx, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				if err := q.DeleteDeadJob(cctx, id); err != nil {
+					return fmt.Errorf("failed to delete dead job (%d). %w", id, err)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("failed to requeue dead job (%d). %w", id, err)
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+
+	return nil
+}
+
+// RequeueAllDeadJobs puts all dead jobs back to the queue and returns their number.
+func (pq *PostgreSQL) RequeueAllDeadJobs(ctx context.Context) (int, error) {
+	n := 0
+	for {
+		jobs, err := pq.ListDeadJobs(ctx, 100)
+		if err != nil {
+			return n, err
+		}
+		if len(jobs) == 0 {
+			return n, nil
+		}
+
+		for _, job := range jobs {
+			if err := pq.RequeueDeadJob(ctx, job.ID); err != nil && !errors.Is(err, ErrJobNotFound) {
+				return n, err
+			}
+			n++
+		}
+	}
+}
+
+// SkipDeadJob marks the dead job as skipped, so it isn't listed anymore.
+func (pq *PostgreSQL) SkipDeadJob(ctx context.Context, id int64) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	n, err := q.SkipDeadJob(cctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to skip dead job (%d). %w", id, err)
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+
+	return nil
+}
+
+// SkipAllDeadJobs marks all dead jobs as skipped and returns their number.
+func (pq *PostgreSQL) SkipAllDeadJobs(ctx context.Context) (int, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	n, err := q.SkipAllDeadJobs(cctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to skip dead jobs. %w", err)
+	}
+
+	return int(n), nil
+}
+
+// OutboxMessage is a Telegram message waiting to be sent. SourceName and ItemKey are empty
+// for coverage messages, which aren't bound to a feed item.
+type OutboxMessage struct {
+	ID           int64
+	Kind         psql.OutboxKind
+	SourceName   string
+	ItemKey      string
+	SourceItemID int32
+	// MessageID is ID of the message to edit, or of the already sent message.
+	MessageID int
+	Attempts  int
+}
+
+// EnqueueOutboxMessage adds message to the outbox. It should be called in the transaction
+// which makes the message needed, so the message is sent only if the transaction is committed.
+func (pq *PostgreSQL) EnqueueOutboxMessage(ctx context.Context, msg OutboxMessage) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.EnqueueOutboxMessage(cctx, psql.EnqueueOutboxMessageParams{
+		Kind:              msg.Kind,
+		Name:              msg.SourceName,
+		ItemKey:           msg.ItemKey,
+		SourceItemID:      msg.SourceItemID,
+		TelegramMessageID: pgtype.Int4{Int32: int32(msg.MessageID), Valid: msg.MessageID != 0},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s outbox message of source item (%d). %w", msg.Kind, msg.SourceItemID, err)
+	}
+
+	return nil
+}
+
+// ClaimOutboxMessage locks the oldest due outbox message for visibilityTimeout.
+// Returns ErrOutboxMessageNotFound if there are no due messages.
+func (pq *PostgreSQL) ClaimOutboxMessage(ctx context.Context, visibilityTimeout time.Duration) (OutboxMessage, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	r, err := q.ClaimOutboxMessage(cctx, pgtype.Interval{Microseconds: visibilityTimeout.Microseconds(), Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OutboxMessage{}, ErrOutboxMessageNotFound
+		}
+		return OutboxMessage{}, fmt.Errorf("failed to claim outbox message. %w", err)
+	}
+
+	return OutboxMessage{
+		ID:           r.OutboxID,
+		Kind:         r.Kind,
+		SourceName:   r.SourceName,
+		ItemKey:      r.ItemKey,
+		SourceItemID: r.SourceItemID,
+		MessageID:    int(r.TelegramMessageID.Int32),
+		Attempts:     int(r.Attempts),
+	}, nil
+}
+
+// SetOutboxMessageID records ID of the sent message, so the message isn't sent again on retry.
+func (pq *PostgreSQL) SetOutboxMessageID(ctx context.Context, msg OutboxMessage, messageID int) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.SetOutboxTelegramMessageID(cctx, psql.SetOutboxTelegramMessageIDParams{
+		OutboxID:          msg.ID,
+		Attempts:          int32(msg.Attempts),
+		TelegramMessageID: pgtype.Int4{Int32: int32(messageID), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set outbox message (%d) Telegram message ID. %w", msg.ID, err)
+	}
+
+	return nil
+}
+
+func (pq *PostgreSQL) CompleteOutboxMessage(ctx context.Context, msg OutboxMessage) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.CompleteOutboxMessage(cctx, psql.CompleteOutboxMessageParams{
+		OutboxID: msg.ID,
+		Attempts: int32(msg.Attempts),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete outbox message (%d). %w", msg.ID, err)
+	}
+
+	return nil
+}
+
+// RetryOutboxMessage schedules the next attempt to send the message at runAt.
+func (pq *PostgreSQL) RetryOutboxMessage(ctx context.Context, msg OutboxMessage, lastError string, runAt time.Time) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.RetryOutboxMessage(cctx, psql.RetryOutboxMessageParams{
+		OutboxID:  msg.ID,
+		Attempts:  int32(msg.Attempts),
+		LastError: pgtype.Text{String: lastError, Valid: true},
+		RunAt:     pgtype.Timestamptz{Time: runAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retry outbox message (%d). %w", msg.ID, err)
+	}
+
+	return nil
+}
+
+// DeferOutboxMessage puts the message back to the outbox to be sent at runAt. Unlike RetryOutboxMessage,
+// the attempt isn't counted.
+func (pq *PostgreSQL) DeferOutboxMessage(ctx context.Context, msg OutboxMessage, reason string, runAt time.Time) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.DeferOutboxMessage(cctx, psql.DeferOutboxMessageParams{
+		OutboxID:  msg.ID,
+		Attempts:  int32(msg.Attempts),
+		LastError: pgtype.Text{String: reason, Valid: true},
+		RunAt:     pgtype.Timestamptz{Time: runAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to defer outbox message (%d). %w", msg.ID, err)
+	}
+
+	return nil
+}
+
+// BuryOutboxMessage moves the message which failed to be sent to the dead state, it isn't sent anymore.
+func (pq *PostgreSQL) BuryOutboxMessage(ctx context.Context, msg OutboxMessage, lastError string) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	err := q.BuryOutboxMessage(cctx, psql.BuryOutboxMessageParams{
+		OutboxID:  msg.ID,
+		Attempts:  int32(msg.Attempts),
+		LastError: pgtype.Text{String: lastError, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to bury outbox message (%d). %w", msg.ID, err)
+	}
+
+	return nil
+}
+
+// DeadOutboxMessage is an outbox message which exceeded its attempts.
+type DeadOutboxMessage struct {
+	OutboxMessage
+	LastError string
+	FailedAt  time.Time
+	// Link is URL of the source item the message is about.
+	Link string
+}
+
+// ListDeadOutboxMessages returns at most limit recently failed dead outbox messages.
+func (pq *PostgreSQL) ListDeadOutboxMessages(ctx context.Context, limit int) ([]DeadOutboxMessage, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	rows, err := q.ListDeadOutboxMessages(cctx, int32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead outbox messages. %w", err)
+	}
+
+	result := make([]DeadOutboxMessage, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, DeadOutboxMessage{
+			OutboxMessage: OutboxMessage{
+				ID:         r.OutboxID,
+				Kind:       r.Kind,
+				SourceName: r.SourceName,
+				Attempts:   int(r.Attempts),
+			},
+			LastError: r.LastError.String,
+			FailedAt:  r.RunAt.Time,
+			Link:      r.Link,
+		})
+	}
+
+	return result, nil
+}
+
+// RequeueDeadOutboxMessage puts the dead outbox message back to the outbox with attempts reset.
+func (pq *PostgreSQL) RequeueDeadOutboxMessage(ctx context.Context, id int64) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	n, err := q.RequeueDeadOutboxMessage(cctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to requeue dead outbox message (%d). %w", id, err)
+	}
+	if n == 0 {
+		return ErrOutboxMessageNotFound
+	}
+
+	return nil
+}
+
+// RequeueAllDeadOutboxMessages puts all dead outbox messages back to the outbox and returns their number.
+func (pq *PostgreSQL) RequeueAllDeadOutboxMessages(ctx context.Context) (int, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	n, err := q.RequeueAllDeadOutboxMessages(cctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead outbox messages. %w", err)
+	}
+
+	return int(n), nil
+}
+
+// SkipDeadOutboxMessage marks the dead outbox message as skipped, so it isn't listed anymore.
+func (pq *PostgreSQL) SkipDeadOutboxMessage(ctx context.Context, id int64) error {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	n, err := q.SkipDeadOutboxMessage(cctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to skip dead outbox message (%d). %w", id, err)
+	}
+	if n == 0 {
+		return ErrOutboxMessageNotFound
+	}
+
+	return nil
+}
+
+// SkipAllDeadOutboxMessages marks all dead outbox messages as skipped and returns their number.
+func (pq *PostgreSQL) SkipAllDeadOutboxMessages(ctx context.Context) (int, error) {
+	q := pq.getQueriesFromContext(ctx)
+	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
+	defer cancel()
+
+	n, err := q.SkipAllDeadOutboxMessages(cctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to skip dead outbox messages. %w", err)
+	}
+
+	return int(n), nil
+}
+
 func (pq *PostgreSQL) CreateReaction(ctx context.Context, sourceItemID int32, reactionType psql.ReactionsType) error {
 	q := pq.getQueriesFromContext(ctx)
 	cctx, cancel := context.WithTimeout(ctx, pq.timeout)
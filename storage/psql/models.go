@@ -11,6 +11,180 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type JobsStatus string
+
+const (
+	JobsStatusQueued  JobsStatus = "queued"
+	JobsStatusRunning JobsStatus = "running"
+	JobsStatusDead    JobsStatus = "dead"
+	JobsStatusSkipped JobsStatus = "skipped"
+)
+
+func (e *JobsStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobsStatus(s)
+	case string:
+		*e = JobsStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobsStatus: %T", src)
+	}
+	return nil
+}
+
+type NullJobsStatus struct {
+	JobsStatus JobsStatus
+	Valid      bool // Valid is true if JobsStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobsStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobsStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobsStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobsStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobsStatus), nil
+}
+
+type JobsType string
+
+const (
+	JobsTypeFetchSource JobsType = "fetch_source"
+	JobsTypeExtractItem JobsType = "extract_item"
+	JobsTypeReportItem  JobsType = "report_item"
+)
+
+func (e *JobsType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobsType(s)
+	case string:
+		*e = JobsType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobsType: %T", src)
+	}
+	return nil
+}
+
+type NullJobsType struct {
+	JobsType JobsType
+	Valid    bool // Valid is true if JobsType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobsType) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobsType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobsType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobsType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobsType), nil
+}
+
+type OutboxKind string
+
+const (
+	OutboxKindReport   OutboxKind = "report"
+	OutboxKindEdit     OutboxKind = "edit"
+	OutboxKindCoverage OutboxKind = "coverage"
+)
+
+func (e *OutboxKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OutboxKind(s)
+	case string:
+		*e = OutboxKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OutboxKind: %T", src)
+	}
+	return nil
+}
+
+type NullOutboxKind struct {
+	OutboxKind OutboxKind
+	Valid      bool // Valid is true if OutboxKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOutboxKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.OutboxKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OutboxKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOutboxKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OutboxKind), nil
+}
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusDead    OutboxStatus = "dead"
+	OutboxStatusSkipped OutboxStatus = "skipped"
+)
+
+func (e *OutboxStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OutboxStatus(s)
+	case string:
+		*e = OutboxStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OutboxStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOutboxStatus struct {
+	OutboxStatus OutboxStatus
+	Valid        bool // Valid is true if OutboxStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOutboxStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OutboxStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OutboxStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOutboxStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OutboxStatus), nil
+}
+
 type ReactionsType string
 
 const (
@@ -53,28 +227,140 @@ func (ns NullReactionsType) Value() (driver.Value, error) {
 	return string(ns.ReactionsType), nil
 }
 
+type SeenItemsStatus string
+
+const (
+	SeenItemsStatusPending   SeenItemsStatus = "pending"
+	SeenItemsStatusExtracted SeenItemsStatus = "extracted"
+	SeenItemsStatusReported  SeenItemsStatus = "reported"
+	SeenItemsStatusFailed    SeenItemsStatus = "failed"
+)
+
+func (e *SeenItemsStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SeenItemsStatus(s)
+	case string:
+		*e = SeenItemsStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SeenItemsStatus: %T", src)
+	}
+	return nil
+}
+
+type NullSeenItemsStatus struct {
+	SeenItemsStatus SeenItemsStatus
+	Valid           bool // Valid is true if SeenItemsStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSeenItemsStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.SeenItemsStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SeenItemsStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSeenItemsStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SeenItemsStatus), nil
+}
+
+type Job struct {
+	JobID       int64
+	Type        JobsType
+	SourceID    int32
+	ItemKey     string
+	Status      JobsStatus
+	Attempts    int32
+	MaxAttempts int32
+	LastError   pgtype.Text
+	RunAt       pgtype.Timestamptz
+	LockedUntil pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+}
+
+type Outbox struct {
+	OutboxID          int64
+	Kind              OutboxKind
+	Status            OutboxStatus
+	SourceID          pgtype.Int4
+	ItemKey           string
+	SourceItemID      int32
+	TelegramMessageID pgtype.Int4
+	Attempts          int32
+	LastError         pgtype.Text
+	RunAt             pgtype.Timestamptz
+	LockedUntil       pgtype.Timestamptz
+	SentAt            pgtype.Timestamptz
+	CreatedAt         pgtype.Timestamp
+}
+
 type Reaction struct {
 	SourceItemID int32
 	Type         ReactionsType
 	CreatedAt    pgtype.Timestamp
 }
 
+type SeenItem struct {
+	SourceID          int32
+	ItemKey           string
+	CreatedAt         pgtype.Timestamp
+	ItemVersion       string
+	SourceItemID      pgtype.Int4
+	Status            SeenItemsStatus
+	Attempts          int32
+	LastError         pgtype.Text
+	TelegramMessageID pgtype.Int4
+	Item              []byte
+}
+
 type Source struct {
-	SourceID      int32
-	Name          string
-	LastFetchedAt pgtype.Timestamp
-	CreatedAt     pgtype.Timestamp
-	UpdatedAt     pgtype.Timestamp
+	SourceID          int32
+	Name              string
+	LastFetchedAt     pgtype.Timestamp
+	CreatedAt         pgtype.Timestamp
+	UpdatedAt         pgtype.Timestamp
+	FeedUrl           pgtype.Text
+	UpdateInterval    pgtype.Interval
+	Category          pgtype.Text
+	HttpEtag          pgtype.Text
+	HttpLastModified  pgtype.Text
+	FetchNotBefore    pgtype.Timestamptz
+	PollInterval      pgtype.Interval
+	NextRunAt         pgtype.Timestamptz
+	SiteUrl           pgtype.Text
+	DiscoveredFeedUrl pgtype.Text
 }
 
 type SourcesItem struct {
+	SourceItemID      int32
+	SourceID          pgtype.Int4
+	Url               pgtype.Text
+	Title             pgtype.Text
+	TextContent       pgtype.Text
+	Excerpt           pgtype.Text
+	Language          pgtype.Text
+	PublishedAt       pgtype.Timestamptz
+	CreatedAt         pgtype.Timestamp
+	TelegramMessageID pgtype.Int4
+	CanonicalUrl      pgtype.Text
+	Fingerprint       pgtype.Int8
+	Updated           bool
+	FingerprintBand0  pgtype.Int4
+	FingerprintBand1  pgtype.Int4
+	FingerprintBand2  pgtype.Int4
+	FingerprintBand3  pgtype.Int4
+}
+
+type SourcesItemsLink struct {
 	SourceItemID int32
-	SourceID     pgtype.Int4
-	Url          pgtype.Text
-	Title        pgtype.Text
-	TextContent  pgtype.Text
-	Excerpt      pgtype.Text
-	Language     pgtype.Text
-	PublishedAt  pgtype.Timestamptz
+	SourceID     int32
 	CreatedAt    pgtype.Timestamp
 }
@@ -11,6 +11,32 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createFeedSource = `-- name: CreateFeedSource :one
+INSERT INTO sources (
+    name, feed_url, update_interval, category, created_at, updated_at
+) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING source_id
+`
+
+type CreateFeedSourceParams struct {
+	Name           string
+	FeedUrl        pgtype.Text
+	UpdateInterval pgtype.Interval
+	Category       pgtype.Text
+}
+
+func (q *Queries) CreateFeedSource(ctx context.Context, arg CreateFeedSourceParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createFeedSource,
+		arg.Name,
+		arg.FeedUrl,
+		arg.UpdateInterval,
+		arg.Category,
+	)
+	var source_id int32
+	err := row.Scan(&source_id)
+	return source_id, err
+}
+
 const createSource = `-- name: CreateSource :one
 INSERT INTO sources (name, created_at, updated_at)
 VALUES ($1, $2, CURRENT_TIMESTAMP)
@@ -29,6 +55,41 @@ func (q *Queries) CreateSource(ctx context.Context, arg CreateSourceParams) (int
 	return source_id, err
 }
 
+const getSourceDiscoveredFeedByName = `-- name: GetSourceDiscoveredFeedByName :one
+SELECT site_url, discovered_feed_url FROM sources WHERE name = $1
+`
+
+type GetSourceDiscoveredFeedByNameRow struct {
+	SiteUrl           pgtype.Text
+	DiscoveredFeedUrl pgtype.Text
+}
+
+func (q *Queries) GetSourceDiscoveredFeedByName(ctx context.Context, name string) (GetSourceDiscoveredFeedByNameRow, error) {
+	row := q.db.QueryRow(ctx, getSourceDiscoveredFeedByName, name)
+	var i GetSourceDiscoveredFeedByNameRow
+	err := row.Scan(&i.SiteUrl, &i.DiscoveredFeedUrl)
+	return i, err
+}
+
+const getSourceHTTPCacheByName = `-- name: GetSourceHTTPCacheByName :one
+SELECT http_etag, http_last_modified, fetch_not_before
+FROM sources
+WHERE name = $1
+`
+
+type GetSourceHTTPCacheByNameRow struct {
+	HttpEtag         pgtype.Text
+	HttpLastModified pgtype.Text
+	FetchNotBefore   pgtype.Timestamptz
+}
+
+func (q *Queries) GetSourceHTTPCacheByName(ctx context.Context, name string) (GetSourceHTTPCacheByNameRow, error) {
+	row := q.db.QueryRow(ctx, getSourceHTTPCacheByName, name)
+	var i GetSourceHTTPCacheByNameRow
+	err := row.Scan(&i.HttpEtag, &i.HttpLastModified, &i.FetchNotBefore)
+	return i, err
+}
+
 const getSourceIdByName = `-- name: GetSourceIdByName :one
 SELECT source_id FROM sources WHERE name = $1
 `
@@ -51,6 +112,144 @@ func (q *Queries) GetSourceLastFetchedAtByName(ctx context.Context, name string)
 	return last_fetched_at, err
 }
 
+const getSourceNextRunAtByName = `-- name: GetSourceNextRunAtByName :one
+SELECT next_run_at FROM sources WHERE name = $1
+`
+
+func (q *Queries) GetSourceNextRunAtByName(ctx context.Context, name string) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getSourceNextRunAtByName, name)
+	var next_run_at pgtype.Timestamptz
+	err := row.Scan(&next_run_at)
+	return next_run_at, err
+}
+
+const getSourcePollIntervalByName = `-- name: GetSourcePollIntervalByName :one
+SELECT poll_interval FROM sources WHERE name = $1
+`
+
+func (q *Queries) GetSourcePollIntervalByName(ctx context.Context, name string) (pgtype.Interval, error) {
+	row := q.db.QueryRow(ctx, getSourcePollIntervalByName, name)
+	var poll_interval pgtype.Interval
+	err := row.Scan(&poll_interval)
+	return poll_interval, err
+}
+
+const listFeedSources = `-- name: ListFeedSources :many
+SELECT name, feed_url, update_interval, category
+FROM sources
+WHERE feed_url IS NOT NULL
+ORDER BY name
+`
+
+type ListFeedSourcesRow struct {
+	Name           string
+	FeedUrl        pgtype.Text
+	UpdateInterval pgtype.Interval
+	Category       pgtype.Text
+}
+
+func (q *Queries) ListFeedSources(ctx context.Context) ([]ListFeedSourcesRow, error) {
+	rows, err := q.db.Query(ctx, listFeedSources)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFeedSourcesRow
+	for rows.Next() {
+		var i ListFeedSourcesRow
+		if err := rows.Scan(
+			&i.Name,
+			&i.FeedUrl,
+			&i.UpdateInterval,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSourcesNextRunAt = `-- name: ListSourcesNextRunAt :many
+SELECT name, next_run_at, last_fetched_at
+FROM sources
+WHERE next_run_at IS NOT NULL
+ORDER BY next_run_at
+`
+
+type ListSourcesNextRunAtRow struct {
+	Name          string
+	NextRunAt     pgtype.Timestamptz
+	LastFetchedAt pgtype.Timestamp
+}
+
+func (q *Queries) ListSourcesNextRunAt(ctx context.Context) ([]ListSourcesNextRunAtRow, error) {
+	rows, err := q.db.Query(ctx, listSourcesNextRunAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSourcesNextRunAtRow
+	for rows.Next() {
+		var i ListSourcesNextRunAtRow
+		if err := rows.Scan(&i.Name, &i.NextRunAt, &i.LastFetchedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setSourceDiscoveredFeedByName = `-- name: SetSourceDiscoveredFeedByName :exec
+UPDATE sources
+SET site_url = $2, discovered_feed_url = $3, updated_at = CURRENT_TIMESTAMP
+WHERE name = $1
+`
+
+type SetSourceDiscoveredFeedByNameParams struct {
+	Name              string
+	SiteUrl           pgtype.Text
+	DiscoveredFeedUrl pgtype.Text
+}
+
+func (q *Queries) SetSourceDiscoveredFeedByName(ctx context.Context, arg SetSourceDiscoveredFeedByNameParams) error {
+	_, err := q.db.Exec(ctx, setSourceDiscoveredFeedByName, arg.Name, arg.SiteUrl, arg.DiscoveredFeedUrl)
+	return err
+}
+
+const setSourceHTTPCacheByName = `-- name: SetSourceHTTPCacheByName :exec
+UPDATE sources
+SET
+    http_etag = $2,
+    http_last_modified = $3,
+    fetch_not_before = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE name = $1
+`
+
+type SetSourceHTTPCacheByNameParams struct {
+	Name             string
+	HttpEtag         pgtype.Text
+	HttpLastModified pgtype.Text
+	FetchNotBefore   pgtype.Timestamptz
+}
+
+func (q *Queries) SetSourceHTTPCacheByName(ctx context.Context, arg SetSourceHTTPCacheByNameParams) error {
+	_, err := q.db.Exec(ctx, setSourceHTTPCacheByName,
+		arg.Name,
+		arg.HttpEtag,
+		arg.HttpLastModified,
+		arg.FetchNotBefore,
+	)
+	return err
+}
+
 const setSourceLastFetchedAtByName = `-- name: SetSourceLastFetchedAtByName :exec
 UPDATE sources
 SET last_fetched_at = $2, updated_at = CURRENT_TIMESTAMP
@@ -66,3 +265,35 @@ func (q *Queries) SetSourceLastFetchedAtByName(ctx context.Context, arg SetSourc
 	_, err := q.db.Exec(ctx, setSourceLastFetchedAtByName, arg.Name, arg.LastFetchedAt)
 	return err
 }
+
+const setSourceNextRunAtByName = `-- name: SetSourceNextRunAtByName :exec
+UPDATE sources
+SET next_run_at = $2, updated_at = CURRENT_TIMESTAMP
+WHERE name = $1
+`
+
+type SetSourceNextRunAtByNameParams struct {
+	Name      string
+	NextRunAt pgtype.Timestamptz
+}
+
+func (q *Queries) SetSourceNextRunAtByName(ctx context.Context, arg SetSourceNextRunAtByNameParams) error {
+	_, err := q.db.Exec(ctx, setSourceNextRunAtByName, arg.Name, arg.NextRunAt)
+	return err
+}
+
+const setSourcePollIntervalByName = `-- name: SetSourcePollIntervalByName :exec
+UPDATE sources
+SET poll_interval = $2, updated_at = CURRENT_TIMESTAMP
+WHERE name = $1
+`
+
+type SetSourcePollIntervalByNameParams struct {
+	Name         string
+	PollInterval pgtype.Interval
+}
+
+func (q *Queries) SetSourcePollIntervalByName(ctx context.Context, arg SetSourcePollIntervalByNameParams) error {
+	_, err := q.db.Exec(ctx, setSourcePollIntervalByName, arg.Name, arg.PollInterval)
+	return err
+}
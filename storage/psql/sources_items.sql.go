@@ -15,38 +15,286 @@ const createSourceItem = `-- name: CreateSourceItem :one
 INSERT INTO sources_items (
     source_id,
     url,
+    canonical_url,
     title,
     text_content,
     excerpt,
     language,
     published_at,
+    fingerprint,
+    updated,
     created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP
-) RETURNING source_item_id
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP
+)
+ON CONFLICT (canonical_url) WHERE canonical_url IS NOT NULL AND NOT updated DO NOTHING
+RETURNING source_item_id
 `
 
 type CreateSourceItemParams struct {
-	SourceID    pgtype.Int4
-	Url         pgtype.Text
-	Title       pgtype.Text
-	TextContent pgtype.Text
-	Excerpt     pgtype.Text
-	Language    pgtype.Text
-	PublishedAt pgtype.Timestamptz
+	SourceID     pgtype.Int4
+	Url          pgtype.Text
+	CanonicalUrl pgtype.Text
+	Title        pgtype.Text
+	TextContent  pgtype.Text
+	Excerpt      pgtype.Text
+	Language     pgtype.Text
+	PublishedAt  pgtype.Timestamptz
+	Fingerprint  pgtype.Int8
+	Updated      bool
 }
 
 func (q *Queries) CreateSourceItem(ctx context.Context, arg CreateSourceItemParams) (int32, error) {
 	row := q.db.QueryRow(ctx, createSourceItem,
 		arg.SourceID,
 		arg.Url,
+		arg.CanonicalUrl,
 		arg.Title,
 		arg.TextContent,
 		arg.Excerpt,
 		arg.Language,
 		arg.PublishedAt,
+		arg.Fingerprint,
+		arg.Updated,
 	)
 	var source_item_id int32
 	err := row.Scan(&source_item_id)
 	return source_item_id, err
 }
+
+const createSourceItemLink = `-- name: CreateSourceItemLink :exec
+INSERT INTO sources_items_links (source_item_id, source_id, created_at)
+VALUES ($1, $2, CURRENT_TIMESTAMP)
+ON CONFLICT (source_item_id, source_id) DO NOTHING
+`
+
+type CreateSourceItemLinkParams struct {
+	SourceItemID int32
+	SourceID     int32
+}
+
+func (q *Queries) CreateSourceItemLink(ctx context.Context, arg CreateSourceItemLinkParams) error {
+	_, err := q.db.Exec(ctx, createSourceItemLink, arg.SourceItemID, arg.SourceID)
+	return err
+}
+
+const getSourceItemCoverage = `-- name: GetSourceItemCoverage :one
+SELECT
+    sources_items.url,
+    sources_items.telegram_message_id,
+    count(DISTINCT sources_items_links.source_id) AS other_sources
+FROM sources_items
+LEFT JOIN sources_items_links
+    ON
+        sources_items.source_item_id = sources_items_links.source_item_id
+        AND sources_items.source_id != sources_items_links.source_id
+WHERE sources_items.source_item_id = $1
+GROUP BY sources_items.source_item_id
+`
+
+type GetSourceItemCoverageRow struct {
+	Url               pgtype.Text
+	TelegramMessageID pgtype.Int4
+	OtherSources      int64
+}
+
+func (q *Queries) GetSourceItemCoverage(ctx context.Context, sourceItemID int32) (GetSourceItemCoverageRow, error) {
+	row := q.db.QueryRow(ctx, getSourceItemCoverage, sourceItemID)
+	var i GetSourceItemCoverageRow
+	err := row.Scan(&i.Url, &i.TelegramMessageID, &i.OtherSources)
+	return i, err
+}
+
+const getSourceItemIDByCanonicalURL = `-- name: GetSourceItemIDByCanonicalURL :one
+SELECT source_item_id
+FROM sources_items
+WHERE canonical_url = $1 AND NOT updated
+`
+
+func (q *Queries) GetSourceItemIDByCanonicalURL(ctx context.Context, canonicalUrl pgtype.Text) (int32, error) {
+	row := q.db.QueryRow(ctx, getSourceItemIDByCanonicalURL, canonicalUrl)
+	var source_item_id int32
+	err := row.Scan(&source_item_id)
+	return source_item_id, err
+}
+
+const getSourceItemsActivity = `-- name: GetSourceItemsActivity :one
+SELECT
+    count(*) AS items,
+    min(sources_items.published_at)::TIMESTAMPTZ AS first_published_at,
+    max(sources_items.published_at)::TIMESTAMPTZ AS last_published_at
+FROM sources_items
+INNER JOIN sources ON sources_items.source_id = sources.source_id
+WHERE sources.name = $1 AND sources_items.published_at > $2
+`
+
+type GetSourceItemsActivityParams struct {
+	Name        string
+	PublishedAt pgtype.Timestamptz
+}
+
+type GetSourceItemsActivityRow struct {
+	Items            int64
+	FirstPublishedAt pgtype.Timestamptz
+	LastPublishedAt  pgtype.Timestamptz
+}
+
+func (q *Queries) GetSourceItemsActivity(ctx context.Context, arg GetSourceItemsActivityParams) (GetSourceItemsActivityRow, error) {
+	row := q.db.QueryRow(ctx, getSourceItemsActivity, arg.Name, arg.PublishedAt)
+	var i GetSourceItemsActivityRow
+	err := row.Scan(&i.Items, &i.FirstPublishedAt, &i.LastPublishedAt)
+	return i, err
+}
+
+const getTelegramMessage = `-- name: GetTelegramMessage :one
+SELECT
+    max(sources_items.source_item_id)::INT AS source_item_id,
+    (array_agg(sources_items.url ORDER BY sources_items.source_item_id DESC))[1]::TEXT AS url,
+    count(DISTINCT sources_items.source_item_id) > 1 AS updated,
+    count(DISTINCT sources_items_links.source_id) AS other_sources,
+    count(reactions.source_item_id) > 0 AS reacted
+FROM sources_items
+LEFT JOIN sources_items_links
+    ON
+        sources_items.source_item_id = sources_items_links.source_item_id
+        AND sources_items.source_id != sources_items_links.source_id
+LEFT JOIN reactions ON sources_items.source_item_id = reactions.source_item_id
+WHERE sources_items.telegram_message_id = $1
+HAVING count(sources_items.source_item_id) > 0
+`
+
+type GetTelegramMessageRow struct {
+	SourceItemID int32
+	Url          pgtype.Text
+	Updated      bool
+	OtherSources int64
+	Reacted      bool
+}
+
+func (q *Queries) GetTelegramMessage(ctx context.Context, telegramMessageID pgtype.Int4) (GetTelegramMessageRow, error) {
+	row := q.db.QueryRow(ctx, getTelegramMessage, telegramMessageID)
+	var i GetTelegramMessageRow
+	err := row.Scan(
+		&i.SourceItemID,
+		&i.Url,
+		&i.Updated,
+		&i.OtherSources,
+		&i.Reacted,
+	)
+	return i, err
+}
+
+const listNearSourceItemFingerprints = `-- name: ListNearSourceItemFingerprints :many
+SELECT source_item_id, fingerprint
+FROM sources_items
+WHERE
+    fingerprint IS NOT NULL
+    AND created_at > $1
+    AND source_id IS DISTINCT FROM (
+        SELECT sources.source_id FROM sources WHERE sources.name = $2
+    )
+    AND (
+        fingerprint_band_0 = $3
+        OR fingerprint_band_1 = $4
+        OR fingerprint_band_2 = $5
+        OR fingerprint_band_3 = $6
+    )
+`
+
+type ListNearSourceItemFingerprintsParams struct {
+	CreatedAt        pgtype.Timestamp
+	Name             string
+	FingerprintBand0 pgtype.Int4
+	FingerprintBand1 pgtype.Int4
+	FingerprintBand2 pgtype.Int4
+	FingerprintBand3 pgtype.Int4
+}
+
+type ListNearSourceItemFingerprintsRow struct {
+	SourceItemID int32
+	Fingerprint  pgtype.Int8
+}
+
+func (q *Queries) ListNearSourceItemFingerprints(ctx context.Context, arg ListNearSourceItemFingerprintsParams) ([]ListNearSourceItemFingerprintsRow, error) {
+	rows, err := q.db.Query(ctx, listNearSourceItemFingerprints,
+		arg.CreatedAt,
+		arg.Name,
+		arg.FingerprintBand0,
+		arg.FingerprintBand1,
+		arg.FingerprintBand2,
+		arg.FingerprintBand3,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNearSourceItemFingerprintsRow
+	for rows.Next() {
+		var i ListNearSourceItemFingerprintsRow
+		if err := rows.Scan(&i.SourceItemID, &i.Fingerprint); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentSourceItemFingerprints = `-- name: ListRecentSourceItemFingerprints :many
+SELECT source_item_id, fingerprint
+FROM sources_items
+WHERE
+    fingerprint IS NOT NULL
+    AND created_at > $1
+    AND source_id IS DISTINCT FROM (
+        SELECT sources.source_id FROM sources WHERE sources.name = $2
+    )
+`
+
+type ListRecentSourceItemFingerprintsParams struct {
+	CreatedAt pgtype.Timestamp
+	Name      string
+}
+
+type ListRecentSourceItemFingerprintsRow struct {
+	SourceItemID int32
+	Fingerprint  pgtype.Int8
+}
+
+func (q *Queries) ListRecentSourceItemFingerprints(ctx context.Context, arg ListRecentSourceItemFingerprintsParams) ([]ListRecentSourceItemFingerprintsRow, error) {
+	rows, err := q.db.Query(ctx, listRecentSourceItemFingerprints, arg.CreatedAt, arg.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRecentSourceItemFingerprintsRow
+	for rows.Next() {
+		var i ListRecentSourceItemFingerprintsRow
+		if err := rows.Scan(&i.SourceItemID, &i.Fingerprint); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setSourceItemTelegramMessageID = `-- name: SetSourceItemTelegramMessageID :exec
+UPDATE sources_items
+SET telegram_message_id = $2
+WHERE source_item_id = $1
+`
+
+type SetSourceItemTelegramMessageIDParams struct {
+	SourceItemID      int32
+	TelegramMessageID pgtype.Int4
+}
+
+func (q *Queries) SetSourceItemTelegramMessageID(ctx context.Context, arg SetSourceItemTelegramMessageIDParams) error {
+	_, err := q.db.Exec(ctx, setSourceItemTelegramMessageID, arg.SourceItemID, arg.TelegramMessageID)
+	return err
+}
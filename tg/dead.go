@@ -0,0 +1,229 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/pavelpuchok/insightcourier/storage"
+)
+
+// deadListLimit limits number of dead jobs and of dead outbox messages listed by /dead command to fit the message.
+const deadListLimit = 20
+
+// maxDeadErrorLen truncates long errors in /dead command output.
+const maxDeadErrorLen = 150
+
+// maxMessageLen is the Telegram limit of the message text length.
+const maxMessageLen = 4096
+
+// outboxIDPrefix distinguishes IDs of dead outbox messages from IDs of dead jobs.
+const outboxIDPrefix = "o"
+
+type DeadJobsStorage interface {
+	ListDeadJobs(ctx context.Context, limit int) ([]storage.DeadJob, error)
+	RequeueDeadJob(ctx context.Context, id int64) error
+	RequeueAllDeadJobs(ctx context.Context) (int, error)
+	SkipDeadJob(ctx context.Context, id int64) error
+	SkipAllDeadJobs(ctx context.Context) (int, error)
+	ListDeadOutboxMessages(ctx context.Context, limit int) ([]storage.DeadOutboxMessage, error)
+	RequeueDeadOutboxMessage(ctx context.Context, id int64) error
+	RequeueAllDeadOutboxMessages(ctx context.Context) (int, error)
+	SkipDeadOutboxMessage(ctx context.Context, id int64) error
+	SkipAllDeadOutboxMessages(ctx context.Context) (int, error)
+}
+
+// deadActions apply the same action to dead jobs and to dead outbox messages.
+type deadActions struct {
+	job         func(context.Context, int64) error
+	allJobs     func(context.Context) (int, error)
+	message     func(context.Context, int64) error
+	allMessages func(context.Context) (int, error)
+}
+
+// handleDeadCommand lists dead jobs and outbox messages: /dead
+func (b *Bot) handleDeadCommand(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	if !b.fromChat(update) {
+		return
+	}
+
+	jobs, err := b.storage.ListDeadJobs(ctx, deadListLimit)
+	if err != nil {
+		slog.Error("failed to list dead jobs", slog.String("error", err.Error()))
+		b.reply(ctx, "Failed to list dead jobs")
+		return
+	}
+	messages, err := b.storage.ListDeadOutboxMessages(ctx, deadListLimit)
+	if err != nil {
+		slog.Error("failed to list dead outbox messages", slog.String("error", err.Error()))
+		b.reply(ctx, "Failed to list dead outbox messages")
+		return
+	}
+
+	b.reply(ctx, deadJobsText(jobs, messages))
+}
+
+// handleRetryCommand puts dead jobs and outbox messages back to the queue: /retry <id|o<id>|all>
+func (b *Bot) handleRetryCommand(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	if !b.fromChat(update) {
+		return
+	}
+	b.reply(ctx, deadJobsAction(ctx, update.Message.Text, "requeued", deadActions{
+		job:         b.storage.RequeueDeadJob,
+		allJobs:     b.storage.RequeueAllDeadJobs,
+		message:     b.storage.RequeueDeadOutboxMessage,
+		allMessages: b.storage.RequeueAllDeadOutboxMessages,
+	}))
+}
+
+// handleSkipCommand marks dead jobs and outbox messages as skipped: /skip <id|o<id>|all>
+func (b *Bot) handleSkipCommand(ctx context.Context, _ *bot.Bot, update *models.Update) {
+	if !b.fromChat(update) {
+		return
+	}
+	b.reply(ctx, deadJobsAction(ctx, update.Message.Text, "skipped", deadActions{
+		job:         b.storage.SkipDeadJob,
+		allJobs:     b.storage.SkipAllDeadJobs,
+		message:     b.storage.SkipDeadOutboxMessage,
+		allMessages: b.storage.SkipAllDeadOutboxMessages,
+	}))
+}
+
+// fromChat reports whether the command is sent to the configured chat, commands from other chats are ignored.
+func (b *Bot) fromChat(update *models.Update) bool {
+	return update.Message != nil && update.Message.Chat.ID == b.chatId
+}
+
+func (b *Bot) reply(ctx context.Context, text string) {
+	_, err := b.b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: b.chatId,
+		Text:   text,
+	})
+	if err != nil {
+		slog.Error("failed to send command reply", slog.String("error", err.Error()))
+	}
+}
+
+func deadJobsText(jobs []storage.DeadJob, messages []storage.DeadOutboxMessage) string {
+	if len(jobs) == 0 && len(messages) == 0 {
+		return "No dead jobs"
+	}
+
+	entries := make([]string, 0, len(jobs)+len(messages))
+	for _, j := range jobs {
+		entries = append(entries, deadEntry(fmt.Sprintf("#%d %s", j.ID, j.Type), j.SourceName, j.Attempts, j.FailedAt, j.Link, j.LastError))
+	}
+	for _, m := range messages {
+		entries = append(entries, deadEntry(fmt.Sprintf("#%s%d %s message", outboxIDPrefix, m.ID, m.Kind), m.SourceName, m.Attempts, m.FailedAt, m.Link, m.LastError))
+	}
+
+	b := &strings.Builder{}
+	for i, e := range entries {
+		entry := &strings.Builder{}
+		if i > 0 {
+			entry.WriteString("\n\n")
+		}
+		entry.WriteString(e)
+
+		// entries which don't fit are only counted, so room for the count is kept unless the entry is the last one
+		more := fmt.Sprintf("\n\n…and %d more", len(entries)-i)
+		reserved := 0
+		if i < len(entries)-1 {
+			reserved = textLen(more)
+		}
+		if textLen(b.String())+textLen(entry.String())+reserved > maxMessageLen {
+			if i == 0 {
+				more = strings.TrimPrefix(more, "\n\n")
+			}
+			b.WriteString(more)
+			break
+		}
+		b.WriteString(entry.String())
+	}
+	return b.String()
+}
+
+func deadEntry(title string, source string, attempts int, failedAt time.Time, link string, lastError string) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s", title)
+	if source != "" {
+		fmt.Fprintf(b, " %s", source)
+	}
+	fmt.Fprintf(b, ", %d attempts, %s", attempts, failedAt.Format(time.DateTime))
+	if link != "" {
+		fmt.Fprintf(b, "\n%s", link)
+	}
+	if r := []rune(lastError); len(r) > maxDeadErrorLen {
+		lastError = string(r[:maxDeadErrorLen]) + "…"
+	}
+	fmt.Fprintf(b, "\n%s", lastError)
+	return b.String()
+}
+
+// textLen returns the text length as counted by Telegram, in UTF-16 code units.
+func textLen(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+func deadJobsAction(ctx context.Context, text string, done string, actions deadActions) string {
+	args := strings.Fields(text)
+	if len(args) != 2 {
+		return "Usage: " + args[0] + " <job-id|o<message-id>|all>"
+	}
+
+	if args[1] == "all" {
+		jobs, err := actions.allJobs(ctx)
+		if err != nil {
+			slog.Error("failed to process dead jobs", slog.String("error", err.Error()))
+			return "Failed to process dead jobs"
+		}
+		messages, err := actions.allMessages(ctx)
+		if err != nil {
+			slog.Error("failed to process dead outbox messages", slog.String("error", err.Error()))
+			return fmt.Sprintf("%d jobs %s, failed to process dead outbox messages", jobs, done)
+		}
+		return fmt.Sprintf("%d jobs and %d outbox messages %s", jobs, messages, done)
+	}
+
+	arg := strings.TrimPrefix(args[1], "#")
+	if v, ok := strings.CutPrefix(arg, outboxIDPrefix); ok {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Sprintf("Invalid outbox message ID %s", args[1])
+		}
+
+		if err := actions.message(ctx, id); err != nil {
+			if errors.Is(err, storage.ErrOutboxMessageNotFound) {
+				return fmt.Sprintf("Dead outbox message #%s%d not found", outboxIDPrefix, id)
+			}
+			slog.Error("failed to process dead outbox message", slog.Int64("outbox.id", id), slog.String("error", err.Error()))
+			return fmt.Sprintf("Failed to process dead outbox message #%s%d", outboxIDPrefix, id)
+		}
+		return fmt.Sprintf("Outbox message #%s%d %s", outboxIDPrefix, id, done)
+	}
+
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return fmt.Sprintf("Invalid job ID %s", args[1])
+	}
+
+	if err := actions.job(ctx, id); err != nil {
+		if errors.Is(err, storage.ErrJobNotFound) {
+			return fmt.Sprintf("Dead job #%d not found", id)
+		}
+		slog.Error("failed to process dead job", slog.Int64("job.id", id), slog.String("error", err.Error()))
+		return fmt.Sprintf("Failed to process dead job #%d", id)
+	}
+	return fmt.Sprintf("Job #%d %s", id, done)
+}
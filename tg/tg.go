@@ -2,6 +2,7 @@ package tg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/go-telegram/bot/models"
 	"github.com/pavelpuchok/insightcourier/config"
 	"github.com/pavelpuchok/insightcourier/feed"
+	"github.com/pavelpuchok/insightcourier/storage"
 	"github.com/pavelpuchok/insightcourier/storage/psql"
 )
 
@@ -19,6 +21,7 @@ type Storage interface {
 	CommitTxInContext(ctx context.Context) error
 	RollbackTxInContext(ctx context.Context) error
 	CreateReaction(ctx context.Context, sourceItemID int32, reactionType psql.ReactionsType) error
+	DeadJobsStorage
 }
 
 type Bot struct {
@@ -33,7 +36,12 @@ func NewBot(storage Storage, cfg config.TelegramConfig) (*Bot, error) {
 		storage: storage,
 	}
 
-	bot, err := bot.New(cfg.APIKey, bot.WithCallbackQueryDataHandler("btn;", bot.MatchTypePrefix, b.handleCallback))
+	bot, err := bot.New(cfg.APIKey,
+		bot.WithCallbackQueryDataHandler("btn;", bot.MatchTypePrefix, b.handleCallback),
+		bot.WithMessageTextHandler("dead", bot.MatchTypeCommandStartOnly, b.handleDeadCommand),
+		bot.WithMessageTextHandler("retry", bot.MatchTypeCommandStartOnly, b.handleRetryCommand),
+		bot.WithMessageTextHandler("skip", bot.MatchTypeCommandStartOnly, b.handleSkipCommand),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create telegram bot. %w", err)
 	}
@@ -91,25 +99,71 @@ func (b *Bot) ListenUpdates(ctx context.Context) {
 	b.b.Start(ctx)
 }
 
-func (b *Bot) Report(ctx context.Context, it feed.Item, sid int32) error {
-	_, err := b.b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: b.chatId,
-		Text:   it.Link,
-		ReplyMarkup: models.InlineKeyboardMarkup{
-			InlineKeyboard: [][]models.InlineKeyboardButton{
-				{
-					{Text: buttonTypeLike.Emoji(), CallbackData: printButtonData(buttonTypeLike, sid)},
-					{Text: buttonTypeDislike.Emoji(), CallbackData: printButtonData(buttonTypeDislike, sid)},
-				},
+func reactionsKeyboard(sid int32) models.InlineKeyboardMarkup {
+	return models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: buttonTypeLike.Emoji(), CallbackData: printButtonData(buttonTypeLike, sid)},
+				{Text: buttonTypeDislike.Emoji(), CallbackData: printButtonData(buttonTypeDislike, sid)},
 			},
 		},
+	}
+}
+
+// messageText renders the reported message. Messages are edited in place, so the text is always
+// built from the whole message state, otherwise an edit would erase what the previous one added.
+func messageText(link string, updated bool, otherSources int) string {
+	b := &strings.Builder{}
+	b.WriteString(link)
+	if updated {
+		b.WriteString("\n✏️ Updated")
+	}
+	switch {
+	case otherSources == 1:
+		b.WriteString("\nAlso covered by 1 other source")
+	case otherSources > 1:
+		fmt.Fprintf(b, "\nAlso covered by %d other sources", otherSources)
+	}
+	return b.String()
+}
+
+// Report sends the feed item to the chat and returns ID of the sent message.
+func (b *Bot) Report(ctx context.Context, it feed.Item, sid int32) (int, error) {
+	msg, err := b.b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      b.chatId,
+		Text:        messageText(it.Link, false, 0),
+		ReplyMarkup: reactionsKeyboard(sid),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to send TG message. %w", err)
+		return 0, fmt.Errorf("failed to send TG message. %w", err)
+	}
+	return msg.ID, nil
+}
+
+// Edit renders previously sent message from its current state. The edit succeeds if the message
+// already shows the state, so it can be repeated safely.
+func (b *Bot) Edit(ctx context.Context, m storage.Message) error {
+	params := &bot.EditMessageTextParams{
+		ChatID:    b.chatId,
+		MessageID: m.ID,
+		Text:      messageText(m.URL, m.Updated, m.OtherSources),
+	}
+	// the message without markup has no buttons, as after the reaction
+	if !m.Reacted {
+		params.ReplyMarkup = reactionsKeyboard(m.SourceItemID)
+	}
+	_, err := b.b.EditMessageText(ctx, params)
+	if err != nil && !isNotModified(err) {
+		return fmt.Errorf("failed to edit TG message (%d). %w", m.ID, err)
 	}
 	return nil
 }
 
+// isNotModified reports whether Telegram rejected the edit because the message is the same.
+func isNotModified(err error) bool {
+	return errors.Is(err, bot.ErrorBadRequest) && strings.Contains(err.Error(), "message is not modified")
+}
+
 func (b *Bot) handleCallback(ctx context.Context, _ *bot.Bot, update *models.Update) {
 	_, err := b.b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
 		CallbackQueryID: update.CallbackQuery.ID,
@@ -10,9 +10,15 @@ import (
 	"time"
 
 	"codeberg.org/readeck/go-readability/v2"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pavelpuchok/insightcourier/canonical"
+	"github.com/pavelpuchok/insightcourier/config"
 	"github.com/pavelpuchok/insightcourier/feed"
+	"github.com/pavelpuchok/insightcourier/fetch"
 	"github.com/pavelpuchok/insightcourier/flaresolverr"
+	"github.com/pavelpuchok/insightcourier/simhash"
 	"github.com/pavelpuchok/insightcourier/storage"
+	"github.com/pavelpuchok/insightcourier/storage/psql"
 )
 
 type Storage interface {
@@ -22,148 +28,597 @@ type Storage interface {
 	GetSourceUpdateTime(ctx context.Context, source string) (*time.Time, error)
 	SetSourceUpdateTime(ctx context.Context, source string, t time.Time) error
 	AddSourceItem(ctx context.Context, item storage.AddSourceItemData) (int32, error)
+	GetSeenItem(ctx context.Context, source string, key string) (*storage.SeenItem, error)
+	SaveSeenItem(ctx context.Context, source string, item storage.SeenItem) error
+	PruneSeenItems(ctx context.Context, source string, before time.Time) (int64, error)
+	SetSourceItemMessageID(ctx context.Context, sourceItemID int32, messageID int) error
+	GetSourceItemIDByCanonicalURL(ctx context.Context, canonicalURL string) (int32, error)
+	LinkSourceItem(ctx context.Context, source string, sourceItemID int32) error
+	ListRecentFingerprints(ctx context.Context, source string, since time.Time) ([]storage.Fingerprint, error)
+	ListNearFingerprints(ctx context.Context, source string, since time.Time, fp uint64) ([]storage.Fingerprint, error)
+	GetSourceItemCoverage(ctx context.Context, sourceItemID int32) (storage.Coverage, error)
+	GetMessage(ctx context.Context, messageID int) (storage.Message, error)
+	GetSourceActivity(ctx context.Context, source string, since time.Time) (storage.SourceActivity, error)
+	SetSourcePollInterval(ctx context.Context, source string, d time.Duration) error
+	EnqueueOutboxMessage(ctx context.Context, msg storage.OutboxMessage) error
+	ClaimOutboxMessage(ctx context.Context, visibilityTimeout time.Duration) (storage.OutboxMessage, error)
+	SetOutboxMessageID(ctx context.Context, msg storage.OutboxMessage, messageID int) error
+	CompleteOutboxMessage(ctx context.Context, msg storage.OutboxMessage) error
+	RetryOutboxMessage(ctx context.Context, msg storage.OutboxMessage, lastError string, runAt time.Time) error
+	DeferOutboxMessage(ctx context.Context, msg storage.OutboxMessage, reason string, runAt time.Time) error
+	BuryOutboxMessage(ctx context.Context, msg storage.OutboxMessage, lastError string) error
 }
 
 type Fetcher interface {
 	Fetch(context.Context, time.Time) ([]feed.Item, error)
 }
 
-type Reporter interface {
-	Report(context.Context, feed.Item, int32) error
+// PageFetcher gets web pages of feed items.
+type PageFetcher interface {
+	Fetch(ctx context.Context, pageURL string) (fetch.Page, error)
 }
 
-type Job struct {
-	SourceName string
+// Source is a feed source processed by Worker.
+type Source struct {
+	Fetcher Fetcher
+	// Pages gets item pages with the fetch strategy of the source.
+	Pages PageFetcher
+	// OnUpdate is one of config.ItemUpdate* policies applied to already reported items updated by the publisher.
+	OnUpdate string
+	// Adaptive is set if the source polling interval follows its activity.
+	Adaptive *AdaptivePolling
+	// Retention is set if reported items are forgotten after it. Fetcher must not return older items again.
+	Retention time.Duration
 }
 
 type Worker struct {
-	Queue       <-chan Job
-	Storage     Storage
-	Reporter    Reporter
-	FlareSolver *flaresolverr.FlareSolverr
-	Fetchers    map[string]Fetcher
+	Queue         *JobQueue
+	Storage       Storage
+	Canonicalizer *canonical.Canonicalizer
+	Dedup         config.DedupConfig
+	Sources       map[string]Source
+	// OnSolverUnavailable is one of config.FlareSolverrUnavailable* policies.
+	OnSolverUnavailable string
 }
 
+// Process runs jobs until ctx is done. The job in progress is finished before returning.
 func (w *Worker) Process(ctx context.Context) {
 	for {
-		select {
-		case <-ctx.Done():
+		job, err := w.Queue.Pop(ctx)
+		if err != nil {
 			return
-		case job := <-w.Queue:
-			ctx, err := w.Storage.BeginTxInContext(ctx)
-			if err != nil {
-				slog.Error("Failed to begin storage transaction", slog.String("error", err.Error()))
-				continue
-			}
+		}
 
-			err = w.processJob(ctx, job)
-			if err != nil {
-				if err := w.Storage.RollbackTxInContext(ctx); err != nil {
-					slog.Error("Failed to rollback storage transaction", slog.String("error", err.Error()))
-				}
-				slog.Error("Failed job processing", slog.String("error", err.Error()))
-				continue
+		w.runJob(context.WithoutCancel(ctx), job)
+	}
+}
+
+// runJob runs the job handler. Handlers remove the job from the queue in the same transaction
+// as their storage changes, so a job interrupted midway is run again from scratch. The job is
+// cancelled once its visibility timeout is exceeded, since it's handed out to another worker by then.
+func (w *Worker) runJob(ctx context.Context, job storage.Job) {
+	err := errors.New("visibility timeout exceeded")
+	// the job was claimed again after the worker running the last attempt didn't finish in time
+	if job.Attempts <= job.MaxAttempts {
+		jctx, cancel := context.WithTimeout(ctx, w.Queue.Config.VisibilityTimeout)
+		err = w.handleJob(jctx, job)
+		cancel()
+	}
+	if err != nil {
+		var unavailable *flaresolverr.CircuitOpenError
+		if errors.As(err, &unavailable) {
+			w.deferJob(ctx, job, err, unavailable.RetryAt)
+			return
+		}
+		// the busy host isn't a failure of the job
+		var throttled *fetch.ThrottledError
+		if errors.As(err, &throttled) {
+			w.deferJob(ctx, job, err, throttled.RetryAt)
+			return
+		}
+		w.failJob(ctx, job, err)
+	}
+}
+
+func (w *Worker) handleJob(ctx context.Context, job storage.Job) error {
+	src, has := w.Sources[job.SourceName]
+	if !has {
+		return fmt.Errorf("unable to find Source with name %s", job.SourceName)
+	}
+
+	switch job.Type {
+	case psql.JobsTypeFetchSource:
+		var slowDown *feed.SlowDownError
+		err := inTx(ctx, w.Storage, func(ctx context.Context) error {
+			err := w.fetchSource(ctx, job, src)
+			// the publisher request to slow down is saved, while the job is retried
+			if errors.As(err, &slowDown) {
+				return nil
 			}
-			err = w.Storage.CommitTxInContext(ctx)
 			if err != nil {
-				slog.Error("Failed to commit storage transaction", slog.String("error", err.Error()))
-				continue
+				return err
 			}
+			return w.Queue.Done(ctx, job)
+		})
+		if err != nil {
+			return err
+		}
+		if slowDown != nil {
+			return slowDown
 		}
+		return nil
+	case psql.JobsTypeExtractItem:
+		return w.extractItem(ctx, job, src)
+	default:
+		return fmt.Errorf("unsupported job type %s", job.Type)
+	}
+}
+
+// failJob schedules retry of the failed job and records the failure in the item state.
+func (w *Worker) failJob(ctx context.Context, job storage.Job, cause error) {
+	nextAttemptAt, err := w.Queue.Fail(ctx, job, cause)
+	if err != nil {
+		slog.Error("Failed to schedule job retry", slog.Int64("job.id", job.ID), slog.String("error", err.Error()))
+	}
+
+	slog.Warn("Failed job processing",
+		slog.Int64("job.id", job.ID),
+		slog.String("job.type", string(job.Type)),
+		slog.String("source.name", job.SourceName),
+		slog.String("item.key", job.ItemKey),
+		slog.Int("attempts", job.Attempts),
+		slog.Time("nextAttemptAt", nextAttemptAt),
+		slog.String("error", cause.Error()),
+	)
+
+	if job.ItemKey == "" {
+		return
+	}
+
+	seen, err := w.Storage.GetSeenItem(ctx, job.SourceName, job.ItemKey)
+	if err != nil {
+		slog.Error("Failed to read failed feed item", slog.String("item.key", job.ItemKey), slog.String("error", err.Error()))
+		return
+	}
+
+	seen.Status = psql.SeenItemsStatusFailed
+	seen.Attempts = job.Attempts
+	seen.LastError = cause.Error()
+	if err := w.Storage.SaveSeenItem(ctx, job.SourceName, *seen); err != nil {
+		slog.Error("Failed to save failed feed item", slog.String("item.key", job.ItemKey), slog.String("error", err.Error()))
+	}
+}
+
+// deferJob puts the job back to the queue until FlareSolverr is expected to be available.
+// The item state is left pending, since the job didn't fail.
+func (w *Worker) deferJob(ctx context.Context, job storage.Job, cause error, runAt time.Time) {
+	if err := w.Queue.Defer(ctx, job, cause, runAt); err != nil {
+		slog.Error("Failed to defer job", slog.Int64("job.id", job.ID), slog.String("error", err.Error()))
+		return
 	}
+
+	slog.Info("Job deferred",
+		slog.Int64("job.id", job.ID),
+		slog.String("job.type", string(job.Type)),
+		slog.String("source.name", job.SourceName),
+		slog.String("item.key", job.ItemKey),
+		slog.Time("runAt", runAt),
+	)
 }
 
-func (w *Worker) processJob(ctx context.Context, job Job) error {
+// inTx runs fn in a storage transaction which is committed only if fn succeeds.
+func inTx(ctx context.Context, s Storage, fn func(context.Context) error) error {
+	ctx, err := s.BeginTxInContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin storage transaction. %w", err)
+	}
+
+	if err := fn(ctx); err != nil {
+		if err := s.RollbackTxInContext(ctx); err != nil {
+			slog.Error("Failed to rollback storage transaction", slog.String("error", err.Error()))
+		}
+		return err
+	}
+
+	if err := s.CommitTxInContext(ctx); err != nil {
+		return fmt.Errorf("failed to commit storage transaction. %w", err)
+	}
+	return nil
+}
+
+// fetchLookback is how long before the last fetch fetchers look for items, so items published
+// out of order or appearing late are found. Items are deduplicated by seen items, the fetch time
+// is used instead of item dates, so future-dated items don't hide the later ones.
+const fetchLookback = 24 * time.Hour
+
+// fetchSource fetches the source feed and enqueues extraction of new and updated items.
+func (w *Worker) fetchSource(ctx context.Context, job storage.Job, src Source) error {
+	fetchedAt := time.Now()
 	t, err := w.Storage.GetSourceUpdateTime(ctx, job.SourceName)
 	if err != nil {
 		if !errors.Is(err, storage.ErrSourceNotFound) {
 			return fmt.Errorf("fail to read storage. %w", err)
 		}
-		tt := time.Now().Add(time.Hour * -1)
+		tt := fetchedAt.Add(time.Hour * -1)
 		t = &tt
 	}
 
-	f, has := w.Fetchers[job.SourceName]
-	if !has {
-		return fmt.Errorf("unable to find Fetcher with name %s", job.SourceName)
+	since := *t
+	if !since.IsZero() {
+		since = since.Add(-fetchLookback)
 	}
 
-	items, err := f.Fetch(ctx, *t)
+	items, err := src.Fetcher.Fetch(ctx, since)
 	if err != nil {
 		return fmt.Errorf("fail to fetch feed. %w", err)
 	}
 
-	var maxT time.Time = *t
 	for _, it := range items {
-		if maxT.Before(it.Time) {
-			maxT = it.Time
+		if err := w.enqueueItem(ctx, job, src, it); err != nil {
+			return err
 		}
+	}
 
-		sid, err := w.parseContent(ctx, job, it)
-		if err != nil {
-			return fmt.Errorf("failed to parse content. Link: %s. %w", it.Link, err)
+	err = w.Storage.SetSourceUpdateTime(ctx, job.SourceName, fetchedAt)
+	if err != nil {
+		return fmt.Errorf("fail to update storage. %w", err)
+	}
+
+	if src.Retention > 0 {
+		if _, err := w.Storage.PruneSeenItems(ctx, job.SourceName, time.Now().Add(-src.Retention)); err != nil {
+			return fmt.Errorf("fail to prune seen items. %w", err)
 		}
+	}
 
-		if err := w.report(ctx, job, it, sid); err != nil {
-			return fmt.Errorf("failed to report feed item. Link: %s. %w", it.Link, err)
+	if src.Adaptive != nil {
+		if err := w.adaptPollInterval(ctx, job, src); err != nil {
+			return fmt.Errorf("fail to adapt poll interval. %w", err)
 		}
 	}
 
-	err = w.Storage.SetSourceUpdateTime(ctx, job.SourceName, maxT)
+	return nil
+}
+
+// itemKey identifies feed item within its source.
+func itemKey(it feed.Item) string {
+	if it.ID != "" {
+		return it.ID
+	}
+	return it.Link
+}
+
+// enqueueItem marks item which wasn't seen before as pending and enqueues its extraction.
+// Items updated since they were seen are handled according to the source OnUpdate policy.
+func (w *Worker) enqueueItem(ctx context.Context, job storage.Job, src Source, it feed.Item) error {
+	seen, err := w.Storage.GetSeenItem(ctx, job.SourceName, itemKey(it))
+	if err != nil && !errors.Is(err, storage.ErrSeenItemNotFound) {
+		return fmt.Errorf("failed to deduplicate feed item. Link: %s. %w", it.Link, err)
+	}
+
+	if seen == nil {
+		seen = &storage.SeenItem{Key: itemKey(it)}
+	} else {
+		if seen.Version == it.Version {
+			return nil
+		}
+
+		switch src.OnUpdate {
+		case config.ItemUpdateEdit, config.ItemUpdateReport:
+		default:
+			seen.Version = it.Version
+			if err := w.Storage.SaveSeenItem(ctx, job.SourceName, *seen); err != nil {
+				return fmt.Errorf("failed to save seen feed item. Link: %s. %w", it.Link, err)
+			}
+			return nil
+		}
+	}
+
+	// updated item is extracted again, MessageID is kept to edit the reported message
+	seen.Version = it.Version
+	seen.Status = psql.SeenItemsStatusPending
+	seen.Attempts = 0
+	seen.LastError = ""
+	seen.SourceItemID = 0
+	seen.Item = it
+	if err := w.Storage.SaveSeenItem(ctx, job.SourceName, *seen); err != nil {
+		return fmt.Errorf("failed to save seen feed item. Link: %s. %w", it.Link, err)
+	}
+
+	_, err = w.Queue.Push(ctx, storage.Job{
+		Type:       psql.JobsTypeExtractItem,
+		SourceName: job.SourceName,
+		ItemKey:    seen.Key,
+	})
 	if err != nil {
-		return fmt.Errorf("fail to update storage. %w", err)
+		return fmt.Errorf("failed to enqueue feed item extraction. Link: %s. %w", it.Link, err)
 	}
 
 	return nil
 }
 
-func (w Worker) report(ctx context.Context, job Job, it feed.Item, sid int32) error {
-	err := w.Reporter.Report(ctx, it, sid)
+// extractItem extracts item content and adds its report to the outbox. Duplicates of already
+// saved items are linked to them and not reported. Updated items are either reported again or
+// the reported message is edited, according to the source OnUpdate policy. The page is fetched
+// and parsed outside of the storage transaction, which only saves the result.
+func (w *Worker) extractItem(ctx context.Context, job storage.Job, src Source) error {
+	seen, err := w.Storage.GetSeenItem(ctx, job.SourceName, job.ItemKey)
 	if err != nil {
-		return fmt.Errorf("fail to report feed item (link: %s): %w", it.Link, err)
+		return fmt.Errorf("failed to read feed item (%s). %w", job.ItemKey, err)
 	}
-	return nil
+	if seen.SourceItemID != 0 {
+		return w.Queue.Done(ctx, job)
+	}
+	it := seen.Item
+
+	// only items never reported are checked for duplicates, updated ones are extracted again
+	c, err := w.parseContent(ctx, job, src, it, seen.MessageID == 0)
+	if err != nil {
+		return fmt.Errorf("failed to parse content. Link: %s. %w", it.Link, err)
+	}
+
+	version := seen.Version
+	return inTx(ctx, w.Storage, func(ctx context.Context) error {
+		seen, err := w.Storage.GetSeenItem(ctx, job.SourceName, job.ItemKey)
+		if err != nil {
+			return fmt.Errorf("failed to read feed item (%s). %w", job.ItemKey, err)
+		}
+		// the item was updated meanwhile, its queued job extracts the new version
+		if seen.SourceItemID != 0 || seen.Version != version {
+			return w.Queue.Done(ctx, job)
+		}
+
+		sid, isNew, err := w.saveContent(ctx, job, c)
+		if err != nil {
+			return err
+		}
+
+		seen.SourceItemID = sid
+		seen.Status = psql.SeenItemsStatusExtracted
+		if !isNew {
+			seen.Status = psql.SeenItemsStatusReported
+		}
+		if err := w.Storage.SaveSeenItem(ctx, job.SourceName, *seen); err != nil {
+			return fmt.Errorf("failed to save seen feed item. Link: %s. %w", it.Link, err)
+		}
+
+		if !isNew {
+			if err := w.updateCoverage(ctx, sid); err != nil {
+				return fmt.Errorf("failed to update coverage of duplicate feed item. Link: %s. %w", it.Link, err)
+			}
+			return w.Queue.Done(ctx, job)
+		}
+
+		msg := storage.OutboxMessage{
+			Kind:         psql.OutboxKindReport,
+			SourceName:   job.SourceName,
+			ItemKey:      seen.Key,
+			SourceItemID: sid,
+		}
+		if src.OnUpdate == config.ItemUpdateEdit && seen.MessageID != 0 {
+			msg.Kind = psql.OutboxKindEdit
+			msg.MessageID = seen.MessageID
+		}
+		if err := w.Storage.EnqueueOutboxMessage(ctx, msg); err != nil {
+			return fmt.Errorf("failed to enqueue feed item report. Link: %s. %w", it.Link, err)
+		}
+
+		return w.Queue.Done(ctx, job)
+	})
 }
 
-func (w Worker) parseContent(ctx context.Context, job Job, it feed.Item) (int32, error) {
-	fsResp, err := w.FlareSolver.Get(it.Link, flaresolverr.WithDisabledMedia())
+// content is the extracted feed item, either a new source item or a duplicate of the saved one.
+type content struct {
+	item storage.AddSourceItemData
+	// duplicateOf is ID of the saved source item duplicated by the feed item, zero for new items.
+	duplicateOf int32
+}
+
+// parseContent extracts the article of the feed item. If dedup is set and the article with the same
+// canonical URL or a similar text was already saved from any source, the content is its duplicate.
+func (w Worker) parseContent(ctx context.Context, job storage.Job, src Source, it feed.Item, dedup bool) (content, error) {
+	canonicalURL, err := w.Canonicalizer.Canonicalize(ctx, it.Link)
 	if err != nil {
-		return 0, fmt.Errorf("fail to get feed item content: %w", err)
+		return content{}, fmt.Errorf("failed to canonicalize link: %w", err)
+	}
+
+	if dedup {
+		sid, found, err := w.findExisting(ctx, job, canonicalURL)
+		if err != nil || found {
+			return content{duplicateOf: sid}, err
+		}
 	}
 
-	if fsResp.Status != "ok" {
-		return 0, fmt.Errorf("unexpected FlareSolverr status: status=%s message=%s", fsResp.Status, fsResp.Message)
+	page, err := src.Pages.Fetch(ctx, it.Link)
+	if err != nil {
+		// the page disallowed by robots.txt won't be allowed on retry, so it's reported with the feed excerpt
+		if errors.Is(err, fetch.ErrDisallowed) {
+			return excerptContent(job, it, canonicalURL, dedup), nil
+		}
+		var unavailable *flaresolverr.CircuitOpenError
+		if errors.As(err, &unavailable) && w.OnSolverUnavailable == config.FlareSolverrUnavailableExcerpt {
+			return excerptContent(job, it, canonicalURL, dedup), nil
+		}
+		return content{}, fmt.Errorf("fail to get feed item content: %w", err)
 	}
 
+	// the page knows its canonical URL better than the feed, and redirects are already followed
+	pageURL := canonicalURL
+	if c := canonical.FromPage(page.URL, page.HTML); c != "" {
+		pageURL = c
+	} else if c, err := canonical.Clean(page.URL); err == nil && page.URL != "" {
+		pageURL = c
+	}
+	if dedup && pageURL != canonicalURL {
+		sid, found, err := w.findExisting(ctx, job, pageURL)
+		if err != nil || found {
+			return content{duplicateOf: sid}, err
+		}
+	}
+	canonicalURL = pageURL
+
 	p := readability.NewParser()
 	u, err := url.ParseRequestURI(it.Link)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse link")
+		return content{}, fmt.Errorf("failed to parse link")
 	}
 
-	article, err := p.Parse(strings.NewReader(fsResp.Solution.Response), u)
+	article, err := p.Parse(strings.NewReader(page.HTML), u)
 	if err != nil {
-		return 0, fmt.Errorf("readability failed to parse article: %w", err)
+		return content{}, fmt.Errorf("readability failed to parse article: %w", err)
 	}
 
 	b := &strings.Builder{}
 	err = article.RenderText(b)
 	if err != nil {
-		return 0, fmt.Errorf("readability failed to render article text: %w", err)
+		return content{}, fmt.Errorf("readability failed to render article text: %w", err)
 	}
 
-	sid, err := w.Storage.AddSourceItem(ctx, storage.AddSourceItemData{
-		SourceName:  job.SourceName,
-		URL:         it.Link,
-		Title:       article.Title(),
-		TextContent: b.String(),
-		Excerpt:     article.Excerpt(),
-		Language:    article.Language(),
-		PublishedAt: it.Time,
-	})
+	text := b.String()
+	var fp uint64
+	if len(simhash.Words(text)) >= simhash.MinWords {
+		fp = simhash.Fingerprint(text)
+	}
+
+	if dedup && fp != 0 && w.Dedup.Mode != config.DedupModeOff {
+		sid, found, err := w.findNearDuplicate(ctx, job, fp)
+		if err != nil || found {
+			return content{duplicateOf: sid}, err
+		}
+	}
+
+	return content{item: storage.AddSourceItemData{
+		SourceName:   job.SourceName,
+		URL:          it.Link,
+		CanonicalURL: canonicalURL,
+		Title:        article.Title(),
+		TextContent:  text,
+		Excerpt:      article.Excerpt(),
+		Language:     article.Language(),
+		PublishedAt:  it.Time,
+		Fingerprint:  fp,
+		Updated:      !dedup,
+	}}, nil
+}
+
+// excerptContent returns the item with its feed description in place of the article content,
+// which can't be fetched. The item isn't checked for near duplicates.
+func excerptContent(job storage.Job, it feed.Item, canonicalURL string, dedup bool) content {
+	text := it.Description
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(it.Description)); err == nil {
+		text = strings.TrimSpace(doc.Text())
+	}
+
+	slog.Info("Feed item content is replaced with excerpt", slog.String("source.name", job.SourceName), slog.String("link", it.Link))
+	return content{item: storage.AddSourceItemData{
+		SourceName:   job.SourceName,
+		URL:          it.Link,
+		CanonicalURL: canonicalURL,
+		Title:        it.Title,
+		TextContent:  text,
+		Excerpt:      text,
+		PublishedAt:  it.Time,
+		Updated:      !dedup,
+	}}
+}
+
+// saveContent saves the new source item or links the duplicated one to the job source. If the item
+// with the same canonical URL was saved meanwhile, e.g. by a worker extracting the same article from
+// another source, the saved item is linked instead and isNew is false.
+func (w Worker) saveContent(ctx context.Context, job storage.Job, c content) (sid int32, isNew bool, err error) {
+	if c.duplicateOf != 0 {
+		return c.duplicateOf, false, w.link(ctx, job, c.duplicateOf)
+	}
+
+	sid, err = w.Storage.AddSourceItem(ctx, c.item)
+	if errors.Is(err, storage.ErrSourceItemAlreadyExists) {
+		sid, found, err := w.findExisting(ctx, job, c.item.CanonicalURL)
+		if err == nil && !found {
+			err = fmt.Errorf("failed to find existing source item by canonical URL %s", c.item.CanonicalURL)
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		return sid, false, w.link(ctx, job, sid)
+	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to save source item: %w", err)
+		return 0, false, fmt.Errorf("failed to save source item: %w", err)
+	}
+
+	return sid, true, nil
+}
+
+// link records that the source item was also published by the job source.
+func (w Worker) link(ctx context.Context, job storage.Job, sid int32) error {
+	if err := w.Storage.LinkSourceItem(ctx, job.SourceName, sid); err != nil {
+		return fmt.Errorf("failed to link duplicate source item: %w", err)
 	}
+	return nil
+}
 
-	return sid, nil
+// findExisting returns the source item with the canonical URL if such item exists.
+func (w Worker) findExisting(ctx context.Context, job storage.Job, canonicalURL string) (int32, bool, error) {
+	sid, err := w.Storage.GetSourceItemIDByCanonicalURL(ctx, canonicalURL)
+	if err != nil {
+		if errors.Is(err, storage.ErrSourceItemNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to find source item by canonical URL: %w", err)
+	}
+
+	slog.Info("Duplicate feed item of existing source item found",
+		slog.String("source.name", job.SourceName),
+		slog.String("canonicalUrl", canonicalURL),
+		slog.Int("sourceItem.id", int(sid)),
+	)
+
+	return sid, true, nil
+}
+
+// findNearDuplicate returns the most similar recent source item of other sources if its fingerprint is close enough.
+func (w Worker) findNearDuplicate(ctx context.Context, job storage.Job, fp uint64) (int32, bool, error) {
+	since := time.Now().Add(-w.Dedup.Window)
+
+	var recent []storage.Fingerprint
+	var err error
+	if w.Dedup.MaxDistance < simhash.Bands {
+		recent, err = w.Storage.ListNearFingerprints(ctx, job.SourceName, since, fp)
+	} else {
+		// near fingerprints may have no equal bands, so all of them are compared
+		recent, err = w.Storage.ListRecentFingerprints(ctx, job.SourceName, since)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find near duplicates: %w", err)
+	}
+
+	var sid int32
+	best := w.Dedup.MaxDistance + 1
+	for _, r := range recent {
+		if d := simhash.Distance(fp, r.Value); d < best {
+			best = d
+			sid = r.SourceItemID
+		}
+	}
+	if sid == 0 {
+		return 0, false, nil
+	}
+
+	slog.Info("Near duplicate feed item of existing source item found",
+		slog.String("source.name", job.SourceName),
+		slog.Int("sourceItem.id", int(sid)),
+		slog.Int("distance", best),
+	)
+
+	return sid, true, nil
+}
+
+// updateCoverage enqueues update of the reported message with number of sources which published the item.
+func (w Worker) updateCoverage(ctx context.Context, sid int32) error {
+	if w.Dedup.Mode != config.DedupModeGroup {
+		return nil
+	}
+
+	return w.Storage.EnqueueOutboxMessage(ctx, storage.OutboxMessage{
+		Kind:         psql.OutboxKindCoverage,
+		SourceItemID: sid,
+	})
 }